@@ -0,0 +1,298 @@
+package togo
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"strings"
+)
+
+// Magic numbers identifying compressed data and archives
+var (
+	gzipMagic  = []byte{0x1f, 0x8b, 0x08}
+	bzip2Magic = []byte("BZh")
+	zipMagic   = []byte("PK\x03\x04")
+	tarMagic   = []byte("ustar")
+
+	// bzip2BlockMagics start the first block of bzip2 data, or the end of
+	// the stream if it is empty, right after the header
+	bzip2BlockMagics = [][]byte{
+		{0x31, 0x41, 0x59, 0x26, 0x53, 0x59},
+		{0x17, 0x72, 0x45, 0x38, 0x50, 0x90},
+	}
+)
+
+// tarMagicOffset is where the magic of the first tar header is found
+const tarMagicOffset = 257
+
+// Kinds of archives returned by archiveKind
+const (
+	archiveZip = "zip"
+	archiveTar = "tar"
+)
+
+// compressionExts are the extensions of compressed files, which do not tell
+// the format of the data
+var compressionExts = []string{".gz", ".bz2"}
+
+// archiveExts are the extensions of the archives looked for in directories
+var archiveExts = []string{".zip", ".tar", ".tgz", ".tar.gz", ".tar.bz2"}
+
+// readCloser reads from a Reader and closes a different Closer
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// isArchiveName checks if the file name has the extension of an archive
+func isArchiveName(file string) bool {
+	file = strings.ToLower(file)
+	for _, ext := range archiveExts {
+		if strings.HasSuffix(file, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// isBzip2 checks if the magic bytes are the header of bzip2 data, i.e. "BZh"
+// and the block size from '1' to '9', followed by the magic of a block
+func isBzip2(magic []byte) bool {
+	hdr := len(bzip2Magic) + 1
+	if len(magic) < hdr || !bytes.HasPrefix(magic, bzip2Magic) || magic[hdr-1] < '1' ||
+		magic[hdr-1] > '9' {
+		return false
+	}
+	for _, bm := range bzip2BlockMagics {
+		if bytes.HasPrefix(magic[hdr:], bm) {
+			return true
+		}
+	}
+	return false
+}
+
+// decompress returns a reader of the decompressed data if r holds gzip or
+// bzip2 compressed data, as told by the magic bytes, or else of the data.
+func decompress(r io.Reader) (*bufio.Reader, error) {
+	br := bufio.NewReader(r)
+	magic, _ := br.Peek(len(bzip2Magic) + 1 + len(bzip2BlockMagics[0]))
+	switch {
+	case bytes.HasPrefix(magic, gzipMagic):
+		log.Println("Found gzip compressed data")
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		return bufio.NewReader(gz), nil
+	case isBzip2(magic):
+		log.Println("Found bzip2 compressed data")
+		return bufio.NewReader(bzip2.NewReader(br)), nil
+	default:
+		return br, nil
+	}
+}
+
+// archiveKind tells from the magic bytes if the file is a zip or, possibly
+// compressed, tar archive. It returns an empty string for any other file.
+func archiveKind(file string) (string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	br, err := decompress(f)
+	if err != nil {
+		return "", err
+	}
+	magic, _ := br.Peek(tarMagicOffset + len(tarMagic))
+	if bytes.HasPrefix(magic, zipMagic) {
+		return archiveZip, nil
+	}
+	if len(magic) == tarMagicOffset+len(tarMagic) && bytes.Equal(magic[tarMagicOffset:], tarMagic) {
+		return archiveTar, nil
+	}
+	return "", nil
+}
+
+// archiveDecoders returns a Decoder for every entry of the archive whose
+// format is known and, if format is given, is the format. The entries are
+// named "archive!entry" as their source. An entry is only opened once its
+// Decoder reads it, so the archive is never held in memory.
+func archiveDecoders(format, file, kind string) ([]Decoder, error) {
+	var decs []Decoder
+	add := func(name string, open func() (io.ReadCloser, error)) error {
+		ef := FormatOf(name)
+		if ef == "" || (format != "" && ef != format) {
+			log.Printf("Skipping entry %s of archive %s\n", name, file)
+			return nil
+		}
+		dec, err := newDecoder(ef, file+"!"+name, &entryReader{open: open})
+		if err != nil {
+			return err
+		}
+		decs = append(decs, dec)
+		return nil
+	}
+
+	switch kind {
+	case archiveZip:
+		zr, err := zip.OpenReader(file)
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		for idx, zf := range zr.File {
+			if zf.FileInfo().IsDir() {
+				continue
+			}
+			idx := idx
+			err = add(zf.Name, func() (io.ReadCloser, error) {
+				return openZipEntry(file, idx)
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+	case archiveTar:
+		rc, err := open(file, nil)
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+		tr := tar.NewReader(rc)
+		cur := &tarCursor{file: file}
+		for idx := 0; ; idx++ {
+			hdr, err := tr.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+			if hdr.Typeflag != tar.TypeReg {
+				continue
+			}
+			idx, n := idx, len(decs)
+			err = add(hdr.Name, func() (io.ReadCloser, error) {
+				return cur.entry(idx)
+			})
+			if err != nil {
+				return nil, err
+			}
+			if len(decs) > n {
+				cur.last = idx
+			}
+		}
+	default:
+		return nil, UnsupportedFormat{format: kind}
+	}
+	log.Printf("Found %d entries to decode in archive %s\n", len(decs), file)
+	return decs, nil
+}
+
+// entryReader reads an entry of an archive. The entry is opened by the first
+// Read and closed once read to the end.
+type entryReader struct {
+	open func() (io.ReadCloser, error)
+	rc   io.ReadCloser
+	err  error
+}
+
+// Read the entry, opening it first if needed
+func (er *entryReader) Read(p []byte) (int, error) {
+	if er.rc == nil && er.err == nil {
+		er.rc, er.err = er.open()
+	}
+	if er.err != nil {
+		return 0, er.err
+	}
+	n, err := er.rc.Read(p)
+	if err == io.EOF {
+		er.rc.Close()
+		er.err = io.EOF
+	}
+	return n, err
+}
+
+// closers closes every Closer, returning the first error
+type closers []io.Closer
+
+// Close every Closer
+func (cs closers) Close() error {
+	var err error
+	for _, c := range cs {
+		if cerr := c.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// openZipEntry opens the entry of the zip archive at idx
+func openZipEntry(file string, idx int) (io.ReadCloser, error) {
+	zr, err := zip.OpenReader(file)
+	if err != nil {
+		return nil, err
+	}
+	rc, err := zr.File[idx].Open()
+	if err != nil {
+		zr.Close()
+		return nil, err
+	}
+	return readCloser{rc, closers{rc, zr}}, nil
+}
+
+// tarCursor reads the entries of a tar archive one after the other, which is
+// the only way to read a tar archive. The readers of the entries share it,
+// so decoding them in order reads the archive once. The archive is closed
+// with the last entry.
+type tarCursor struct {
+	file string
+	last int
+	rc   io.ReadCloser
+	tr   *tar.Reader
+	next int
+}
+
+// entry returns the reader of the entry at idx, counting every header of the
+// archive. The archive is read again from the start if the entry is passed.
+func (tc *tarCursor) entry(idx int) (io.ReadCloser, error) {
+	if tc.tr == nil || idx < tc.next {
+		tc.Close()
+		rc, err := open(tc.file, nil)
+		if err != nil {
+			return nil, err
+		}
+		tc.rc, tc.tr, tc.next = rc, tar.NewReader(rc), 0
+	}
+	for ; tc.next <= idx; tc.next++ {
+		if _, err := tc.tr.Next(); err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			tc.Close()
+			return nil, err
+		}
+	}
+	if idx == tc.last {
+		return readCloser{tc.tr, tc}, nil
+	}
+	return ioutil.NopCloser(tc.tr), nil
+}
+
+// Close the archive
+func (tc *tarCursor) Close() error {
+	if tc.rc == nil {
+		return nil
+	}
+	err := tc.rc.Close()
+	tc.rc, tc.tr = nil, nil
+	return err
+}
@@ -0,0 +1,167 @@
+package togo
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestArchiveKind(t *testing.T) {
+	tests := []struct {
+		tc   string
+		file string
+		kind string
+	}{
+		{"Gzipped Tar", "samples/archive/responses.tar.gz", archiveTar},
+		{"Zip", "samples/archive/responses.zip", archiveZip},
+		{"Gzipped File", "samples/archive/user-2.json.gz", ""},
+		{"Plain File", "samples/json/users.json", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tc, func(t *testing.T) {
+			kind, err := archiveKind(tt.file)
+			if err != nil {
+				t.Fatalf("TC: %s: Did not expect error but got %#v", tt.tc, err)
+			}
+			if kind != tt.kind {
+				t.Errorf("TC: %s: Expected %q but got %q", tt.tc, tt.kind, kind)
+			}
+		})
+	}
+}
+
+func TestNewFileDecoders_Archives(t *testing.T) {
+	tests := []struct {
+		tc      string
+		input   string
+		sources []string
+	}{
+		{"Gzipped Tar", "samples/archive/responses.tar.gz", []string{
+			"samples/archive/responses.tar.gz!user-1.json",
+			"samples/archive/responses.tar.gz!user-2.json",
+		}},
+		{"Zip", "samples/archive/responses.zip", []string{
+			"samples/archive/responses.zip!user-3.json",
+			"samples/archive/responses.zip!user-1.json",
+		}},
+		{"Gzipped File", "samples/archive/user-2.json.gz", []string{
+			"samples/archive/user-2.json.gz",
+		}},
+		{"Bzipped File", "samples/archive/user-3.json.bz2", []string{
+			"samples/archive/user-3.json.bz2",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tc, func(t *testing.T) {
+			decs, err := NewFileDecoders("", tt.input)
+			if err != nil {
+				t.Fatalf("TC: %s: Did not expect error but got %#v", tt.tc, err)
+			}
+			if len(decs) != len(tt.sources) {
+				t.Fatalf("TC: %s: Expected %d decoders but got %d", tt.tc, len(tt.sources), len(decs))
+			}
+			for i, dec := range decs {
+				if src := dec.(Sourcer).Source(); src != tt.sources[i] {
+					t.Errorf("TC: %s: Expected source %s but got %s", tt.tc, tt.sources[i], src)
+				}
+				dd, err := dec.Decode()
+				if err != nil {
+					t.Fatalf("TC: %s: Decode failed: %+v", tt.tc, err)
+				}
+				if _, ok := dd.mapData["id"]; !ok {
+					t.Errorf("TC: %s: Expected a user to be decoded, got %+v", tt.tc, dd)
+				}
+			}
+		})
+	}
+}
+
+func TestParse_Archives(t *testing.T) {
+	decs, err := NewFileDecoders("", "samples/archive")
+	if err != nil {
+		t.Fatalf("NewFileDecoders failed: %+v", err)
+	}
+	if err = Parse(decs...); err != nil {
+		t.Fatalf("Parse failed: %+v", err)
+	}
+	for _, name := range []string{"id", "email", "phone", "roles", "address"} {
+		if _, ok := NameStructCache["Document"].Fields[name]; !ok {
+			t.Errorf("Field %s missing in the merged Document", name)
+		}
+	}
+}
+
+func TestArchiveDecoders_Lazy(t *testing.T) {
+	tests := []struct {
+		tc    string
+		file  string
+		kind  string
+		order []int
+	}{
+		{"Tar In Order", "samples/archive/responses.tar.gz", archiveTar, []int{0, 1}},
+		{"Tar Out Of Order", "samples/archive/responses.tar.gz", archiveTar, []int{1, 0}},
+		{"Zip Out Of Order", "samples/archive/responses.zip", archiveZip, []int{1, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tc, func(t *testing.T) {
+			decs, err := archiveDecoders("", tt.file, tt.kind)
+			if err != nil {
+				t.Fatalf("TC: %s: Did not expect error but got %#v", tt.tc, err)
+			}
+			for _, dec := range decs {
+				if er := dec.(*JSON).reader.(*entryReader); er.rc != nil {
+					t.Errorf("TC: %s: Expected %s to be opened when decoded", tt.tc, dec.(*JSON).File)
+				}
+			}
+			for _, i := range tt.order {
+				dd, err := decs[i].Decode()
+				if err != nil {
+					t.Fatalf("TC: %s: Decode of %d failed: %+v", tt.tc, i, err)
+				}
+				if _, ok := dd.mapData["id"]; !ok {
+					t.Errorf("TC: %s: Expected a user to be decoded, got %+v", tt.tc, dd)
+				}
+			}
+		})
+	}
+}
+
+func TestDecompress(t *testing.T) {
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	zw.Write([]byte(`{"id": 1}`))
+	zw.Close()
+	bz, err := ioutil.ReadFile("samples/archive/user-3.json.bz2")
+	if err != nil {
+		t.Fatalf("ReadFile failed: %+v", err)
+	}
+	tests := []struct {
+		tc     string
+		data   []byte
+		prefix string
+	}{
+		{"Gzip", gz.Bytes(), `{"id": 1}`},
+		{"Bzip2", bz, "{"},
+		{"Text Starting Like Bzip2", []byte("BZh9 is not a block"), "BZh9 is"},
+		{"Text With Bzip2 Header", []byte("BZhx"), "BZhx"},
+		{"Gzip Magic Without Deflate", []byte{0x1f, 0x8b, 'a', 'b'}, "\x1f\x8bab"},
+		{"Plain", []byte(`{"id": 1}`), `{"id": 1}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tc, func(t *testing.T) {
+			br, err := decompress(bytes.NewReader(tt.data))
+			if err != nil {
+				t.Fatalf("TC: %s: Did not expect error but got %#v", tt.tc, err)
+			}
+			data, err := ioutil.ReadAll(br)
+			if err != nil {
+				t.Fatalf("TC: %s: Did not expect error but got %#v", tt.tc, err)
+			}
+			if !strings.HasPrefix(string(data), tt.prefix) {
+				t.Errorf("TC: %s: Expected %q to start with %q", tt.tc, data, tt.prefix)
+			}
+		})
+	}
+}
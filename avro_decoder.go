@@ -0,0 +1,293 @@
+package togo
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+)
+
+// Avro type structure to convert an Avro schema (.avsc) to go structs. The
+// structs are built from the schema: records become structs named after the
+// record with avro tags, enums become strings documented with their symbols,
+// arrays and maps become slices and maps, unions of null and a type become a
+// pointer to the type and other unions an interface{}. Fixed types are byte
+// arrays and the logical types map onto time.Time (timestamps and dates),
+// time.Duration (times of day) and *big.Rat (decimals). A file can hold a
+// single schema or a union of schemas, whose last record is the root struct.
+type Avro struct {
+	File   string
+	reader io.Reader
+}
+
+// NewAvroReader creates an Avro decoder reading from r instead of a file.
+// The reader is consumed by the first Build.
+func NewAvroReader(r io.Reader) *Avro {
+	return &Avro{reader: r}
+}
+
+// NewAvroBytes creates an Avro decoder for the in-memory schema
+func NewAvroBytes(b []byte) *Avro {
+	return NewAvroReader(bytes.NewReader(b))
+}
+
+// Decode is not supported since an Avro schema describes types and holds no
+// data to infer them from. The parser uses Build instead.
+func (a *Avro) Decode() (DecodedData, error) {
+	return DecodedData{}, errors.New("An Avro schema has no data to decode, its structs are built")
+}
+
+// Build the structs described by the schema into the caches and return the
+// root struct
+func (a *Avro) Build(tr tracker) (*GoStruct, error) {
+	f, err := open(a.File, a.reader)
+	if err != nil {
+		log.Println("Error while reading file", err)
+		return nil, err
+	}
+	defer f.Close()
+
+	var schema interface{}
+	if err = json.NewDecoder(f).Decode(&schema); err != nil {
+		log.Println("Error while decoding", err)
+		return nil, err
+	}
+	b := &avroBuilder{source: tr.source, named: make(map[string]*Field),
+		building: make(map[string]bool)}
+	roots := []interface{}{schema}
+	if union, ok := schema.([]interface{}); ok {
+		roots = union
+	}
+	var root *GoStruct
+	for _, s := range roots {
+		f := &Field{sliceNesting: -1}
+		if err = b.typeOf(f, s, "", tr.level); err != nil {
+			return nil, err
+		}
+		if f.dataType == Map {
+			root = NameStructCache[f.dtStruct]
+		}
+	}
+	if root == nil {
+		return nil, errors.New("The schema has no record")
+	}
+	return root, nil
+}
+
+// Source of this Avro instance, the file name or empty if read from a reader
+func (a *Avro) Source() string {
+	return a.File
+}
+
+// Annotate a field name with its avro tag
+func (a *Avro) Annotate(name string) string {
+	return "avro:" + name
+}
+
+// avroBuilder builds the structs of the records of a schema
+type avroBuilder struct {
+	source string
+	// named maps the full names of the named types (records, enums and
+	// fixed) onto a field of the type, copied by the references to the type
+	named map[string]*Field
+	// building has the records being built, which recursive schemas refer
+	// to by pointer
+	building map[string]bool
+}
+
+// avroPrimitives maps the primitive types of Avro onto their field
+var avroPrimitives = map[string]Field{
+	"null":    {dataType: Interface},
+	"boolean": {dataType: Bool},
+	"int":     {dataType: Int},
+	"long":    {dataType: Int64},
+	"float":   {dataType: Float64},
+	"double":  {dataType: Float64},
+	"string":  {dataType: String},
+	"bytes":   {dataType: Named, dtStruct: "[]byte"},
+}
+
+// avroLogicalTypes maps the logical types of Avro onto their go type
+var avroLogicalTypes = map[string]string{
+	"timestamp-millis":       "time.Time",
+	"timestamp-micros":       "time.Time",
+	"local-timestamp-millis": "time.Time",
+	"local-timestamp-micros": "time.Time",
+	"date":                   "time.Time",
+	"time-millis":            "time.Duration",
+	"time-micros":            "time.Duration",
+	"decimal":                "*big.Rat",
+}
+
+// fullName returns the full name of a named type, looked up in the namespace
+// ns unless the name is qualified already
+func fullName(name, ns string) string {
+	if strings.Contains(name, ".") || ns == "" {
+		return name
+	}
+	return ns + "." + name
+}
+
+// shortName returns the name of a named type without its namespace
+func shortName(name string) string {
+	return name[strings.LastIndex(name, ".")+1:]
+}
+
+// typeOf sets the type of the field from its schema, found in namespace ns.
+// Records are built as structs at the level.
+func (b *avroBuilder) typeOf(f *Field, s interface{}, ns string, level int) error {
+	switch sv := s.(type) {
+	case string:
+		if p, ok := avroPrimitives[sv]; ok {
+			f.dataType, f.dtStruct = p.dataType, p.dtStruct
+			return nil
+		}
+		full := fullName(sv, ns)
+		t, ok := b.named[full]
+		if !ok {
+			if t, ok = b.named[sv]; !ok {
+				return fmt.Errorf("Unknown type %s", sv)
+			}
+			full = sv
+		}
+		f.dataType, f.dtStruct, f.sliceNesting = t.dataType, t.dtStruct, t.sliceNesting
+		f.comment = t.comment
+		if b.building[full] {
+			pointer(f)
+		}
+		return nil
+	case []interface{}:
+		var types []interface{}
+		null := false
+		for _, u := range sv {
+			if u == "null" {
+				null = true
+				continue
+			}
+			types = append(types, u)
+		}
+		if len(types) != 1 {
+			f.dataType = Interface
+			return nil
+		}
+		if err := b.typeOf(f, types[0], ns, level); err != nil {
+			return err
+		}
+		if null {
+			pointer(f)
+		}
+		return nil
+	case map[string]interface{}:
+		return b.complexType(f, sv, ns, level)
+	default:
+		return fmt.Errorf("Invalid schema %v", s)
+	}
+}
+
+// complexType sets the type of the field from a schema given as an object
+func (b *avroBuilder) complexType(f *Field, s map[string]interface{}, ns string, level int) error {
+	lt, logical := avroLogicalTypes[fmt.Sprint(s["logicalType"])]
+	t := s["type"]
+	name, _ := s["name"].(string)
+	if n, ok := s["namespace"].(string); ok {
+		ns = n
+	}
+	full := fullName(name, ns)
+	if strings.Contains(name, ".") {
+		ns = name[:strings.LastIndex(name, ".")]
+	}
+
+	switch t {
+	case "record", "error":
+		if name == "" {
+			return errors.New("record without name")
+		}
+		sn := shortName(name)
+		b.named[full] = &Field{dataType: Map, dtStruct: sn, sliceNesting: -1}
+		b.building[full] = true
+		defer delete(b.building, full)
+		if _, err := b.record(sn, s, ns, level); err != nil {
+			return err
+		}
+		f.dataType, f.dtStruct = Map, sn
+	case "enum":
+		syms, _ := s["symbols"].([]interface{})
+		vals := make([]string, len(syms))
+		for i, sym := range syms {
+			vals[i] = fmt.Sprint(sym)
+		}
+		f.dataType = String
+		f.comment = "One of: " + strings.Join(vals, ", ")
+		b.named[full] = &Field{dataType: String, sliceNesting: -1, comment: f.comment}
+	case "fixed":
+		size, _ := s["size"].(float64)
+		f.dataType, f.dtStruct = Named, fmt.Sprintf("[%d]byte", int(size))
+		if logical {
+			f.dtStruct = lt
+		}
+		b.named[full] = &Field{dataType: Named, dtStruct: f.dtStruct, sliceNesting: -1}
+	case "array":
+		elem := &Field{sliceNesting: -1}
+		if err := b.typeOf(elem, s["items"], ns, level); err != nil {
+			return err
+		}
+		f.dataType = Slice
+		if elem.dataType == Slice {
+			f.dtStruct, f.sliceNesting = elem.dtStruct, elem.sliceNesting+1
+		} else {
+			f.dtStruct, f.sliceNesting = elem.elemType(), 1
+		}
+		f.comment = elem.comment
+	case "map":
+		elem := &Field{sliceNesting: -1}
+		if err := b.typeOf(elem, s["values"], ns, level); err != nil {
+			return err
+		}
+		f.dataType, f.dtStruct = Named, "map[string]"+elem.elemType()
+	default:
+		if logical {
+			f.dataType, f.dtStruct = Named, lt
+			return nil
+		}
+		// A primitive, or a type nested in the type attribute
+		return b.typeOf(f, t, ns, level)
+	}
+	return nil
+}
+
+// record builds the struct of a record and caches it
+func (b *avroBuilder) record(name string, s map[string]interface{}, ns string, level int) (*GoStruct, error) {
+	gs := &GoStruct{Name: name, Level: level}
+	fields, _ := s["fields"].([]interface{})
+	for _, fs := range fields {
+		fm, ok := fs.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%s: invalid field %v", name, fs)
+		}
+		fn, _ := fm["name"].(string)
+		f := &Field{name: fn, sliceNesting: -1}
+		if err := b.typeOf(f, fm["type"], ns, level+1); err != nil {
+			return nil, fmt.Errorf("%s.%s: %v", name, fn, err)
+		}
+		var lines []string
+		if doc, ok := fm["doc"].(string); ok && strings.TrimSpace(doc) != "" {
+			lines = append(lines, strings.TrimSpace(doc))
+		}
+		if f.comment != "" {
+			lines = append(lines, f.comment)
+		}
+		f.comment = strings.Join(lines, "\n")
+		f.Annotate("avro:" + fn)
+		f.addSource(b.source)
+		if err := gs.AddField(f); err != nil {
+			return nil, err
+		}
+	}
+	if err := Cache(gs); err != nil {
+		return nil, err
+	}
+	return gs, nil
+}
@@ -0,0 +1,92 @@
+package togo
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestAvro_Build(t *testing.T) {
+	tests := []struct {
+		tc     string
+		schema string
+		exp    []string
+		expErr bool
+	}{
+		{"Primitives", `{"type": "record", "name": "Point", "fields": [{"name": "x", "type": "double"},
+			{"name": "label", "type": ["null", "string"]}, {"name": "raw", "type": "bytes"}]}`,
+			[]string{"type Point struct {", "\tX float64 `avro:\"x\"`", "\tLabel *string `avro:\"label\"`",
+				"\tRaw []byte `avro:\"raw\"`"}, false},
+		{"Named References", `{"type": "record", "name": "a.Pair", "fields": [
+			{"name": "left", "type": {"type": "fixed", "name": "Hash", "size": 4}},
+			{"name": "right", "type": "a.Hash"}, {"name": "all", "type": {"type": "array", "items": "Hash"}}]}`,
+			[]string{"\tLeft [4]byte `avro:\"left\"`", "\tRight [4]byte `avro:\"right\"`",
+				"\tAll [][4]byte `avro:\"all\"`"}, false},
+		{"Union Of Schemas", `[{"type": "enum", "name": "Kind", "symbols": ["A", "B"]},
+			{"type": "record", "name": "Item", "fields": [{"name": "kind", "type": "Kind"},
+			{"name": "at", "type": {"type": "int", "logicalType": "date"}}]}]`,
+			[]string{"\t// One of: A, B\n\tKind string `avro:\"kind\"`", "\tAt time.Time `avro:\"at\"`"}, false},
+		{"Unknown Type", `{"type": "record", "name": "R", "fields": [{"name": "x", "type": "Missing"}]}`, nil, true},
+		{"No Record", `"string"`, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tc, func(t *testing.T) {
+			LevelOrderCache = make(map[int][]*GoStruct)
+			NameStructCache = make(map[string]*GoStruct)
+			_, err := NewAvroBytes([]byte(tt.schema)).Build(tracker{name: rootName})
+			if tt.expErr {
+				if err == nil {
+					t.Errorf("TC: %s: Expected error but did not get any error", tt.tc)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("TC: %s: Did not expect error but got %+v", tt.tc, err)
+			}
+			var buf bytes.Buffer
+			if err = WriteStructs(&buf); err != nil {
+				t.Fatalf("TC: %s: WriteStructs failed: %+v", tt.tc, err)
+			}
+			for _, exp := range tt.exp {
+				if !strings.Contains(buf.String(), exp) {
+					t.Errorf("TC: %s: Expected %q in the structs but got:\n%s", tt.tc, exp, buf.String())
+				}
+			}
+		})
+	}
+}
+
+func TestParse_Avro(t *testing.T) {
+	dec, err := NewFileDecoder("", "samples/avro/order.avsc")
+	if err != nil {
+		t.Fatalf("NewFileDecoder failed: %+v", err)
+	}
+	if err = Parse(dec); err != nil {
+		t.Fatalf("Parse failed: %+v", err)
+	}
+	var buf bytes.Buffer
+	if err = WriteStructs(&buf); err != nil {
+		t.Fatalf("WriteStructs failed: %+v", err)
+	}
+	out := buf.String()
+	for _, exp := range []string{
+		"type Order struct {",
+		"\t// Unique id of the order\n\tID int64 `avro:\"id\"`",
+		"\t// One of: NEW, PAID, SHIPPED\n\tStatus string `avro:\"status\"`",
+		"\tPlacedAt time.Time `avro:\"placed_at\"`",
+		"\tTotal *big.Rat `avro:\"total\"`",
+		"\tChecksum [16]byte `avro:\"checksum\"`",
+		"\tCustomer Customer `avro:\"customer\"`",
+		"\tLines []Line `avro:\"lines\"`",
+		"\tTags map[string]string `avro:\"tags\"`",
+		"\tPreviousStatus *string `avro:\"previous_status\"`",
+		"\tReplaces *Order `avro:\"replaces\"`",
+		"\tNote interface{} `avro:\"note\"`",
+		"\tEmail *string `avro:\"email\"`",
+		"\tPrice *big.Rat `avro:\"price\"`",
+	} {
+		if !strings.Contains(out, exp) {
+			t.Errorf("Expected %q in the structs but got:\n%s", exp, out)
+		}
+	}
+}
@@ -0,0 +1,562 @@
+package togo
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"strconv"
+	"time"
+)
+
+// The BSON specific types. They only carry the type of a value into ToField,
+// which spells them like the types of the MongoDB go driver.
+type (
+	objectID      [12]byte
+	decimal128    [16]byte
+	bsonTimestamp struct{ t, i uint32 }
+	bsonRegex     struct{ pattern, options string }
+)
+
+// bsonPrimitive is the package of the MongoDB go driver with the BSON types
+const bsonPrimitive = "go.mongodb.org/mongo-driver/bson/primitive"
+
+func init() {
+	registerNamedType(objectID{}, "primitive.ObjectID", bsonPrimitive)
+	registerNamedType(decimal128{}, "primitive.Decimal128", bsonPrimitive)
+	registerNamedType(bsonTimestamp{}, "primitive.Timestamp", bsonPrimitive)
+	registerNamedType(bsonRegex{}, "primitive.Regex", bsonPrimitive)
+}
+
+// Element types of BSON, see http://bsonspec.org/spec.html
+const (
+	bsonDouble     = 0x01
+	bsonString     = 0x02
+	bsonDocument   = 0x03
+	bsonArray      = 0x04
+	bsonBinary     = 0x05
+	bsonUndefined  = 0x06
+	bsonObjectID   = 0x07
+	bsonBool       = 0x08
+	bsonDateTime   = 0x09
+	bsonNull       = 0x0A
+	bsonRegexp     = 0x0B
+	bsonDBPointer  = 0x0C
+	bsonJavaScript = 0x0D
+	bsonSymbol     = 0x0E
+	bsonCodeScope  = 0x0F
+	bsonInt32      = 0x10
+	bsonTimestampT = 0x11
+	bsonInt64      = 0x12
+	bsonDecimal128 = 0x13
+	bsonMinKey     = 0xFF
+	bsonMaxKey     = 0x7F
+)
+
+// BSON type structure to convert raw BSON documents, e.g. a mongodump file,
+// to go struct. Every document of the file is a sample of the root type.
+type BSON struct {
+	File   string
+	reader io.Reader
+}
+
+// NewBSONReader creates a BSON decoder reading from r instead of a file.
+// The reader is consumed by the first Decode.
+func NewBSONReader(r io.Reader) *BSON {
+	return &BSON{reader: r}
+}
+
+// NewBSONBytes creates a BSON decoder for the in-memory data
+func NewBSONBytes(b []byte) *BSON {
+	return NewBSONReader(bytes.NewReader(b))
+}
+
+// Decode this BSON instance into decodedData
+func (b *BSON) Decode() (DecodedData, error) {
+
+	dd := new(DecodedData)
+	f, err := open(b.File, b.reader)
+	if err != nil {
+		log.Println("Error while reading file", err)
+		return *dd, err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	for {
+		var size int32
+		err := binary.Read(r, binary.LittleEndian, &size)
+		if err == io.EOF {
+			break
+		}
+		if err != nil || size < 5 {
+			log.Println("Error while reading document size", err)
+			return *dd, errors.New("Invalid BSON document size")
+		}
+		doc := make([]byte, size)
+		binary.LittleEndian.PutUint32(doc, uint32(size))
+		if _, err = io.ReadFull(r, doc[4:]); err != nil {
+			log.Println("Error while reading document", err)
+			return *dd, err
+		}
+		p := bsonParser{data: doc}
+		mp, err := p.document()
+		if err != nil {
+			log.Println("Error while decoding", err)
+			return *dd, err
+		}
+		dd.documents = append(dd.documents, DecodedData{mapData: mp})
+	}
+	if len(dd.documents) == 0 {
+		return *dd, io.ErrUnexpectedEOF
+	}
+	if len(dd.documents) == 1 {
+		return dd.documents[0], nil
+	}
+	return *dd, nil
+}
+
+// Source of this BSON instance, the file name or empty if read from a reader
+func (b *BSON) Source() string {
+	return b.File
+}
+
+// Annotate a field name with its bson tag
+func (b *BSON) Annotate(name string) string {
+	return "bson:" + name
+}
+
+// bsonParser reads the elements of a BSON document
+type bsonParser struct {
+	data []byte
+	pos  int
+}
+
+var errBSONShort = errors.New("BSON document is truncated")
+
+func (p *bsonParser) next(n int) ([]byte, error) {
+	if n < 0 || p.pos+n > len(p.data) {
+		return nil, errBSONShort
+	}
+	b := p.data[p.pos : p.pos+n]
+	p.pos += n
+	return b, nil
+}
+
+func (p *bsonParser) int32() (int32, error) {
+	b, err := p.next(4)
+	if err != nil {
+		return 0, err
+	}
+	return int32(binary.LittleEndian.Uint32(b)), nil
+}
+
+func (p *bsonParser) int64() (int64, error) {
+	b, err := p.next(8)
+	if err != nil {
+		return 0, err
+	}
+	return int64(binary.LittleEndian.Uint64(b)), nil
+}
+
+func (p *bsonParser) cstring() (string, error) {
+	end := bytes.IndexByte(p.data[p.pos:], 0)
+	if end < 0 {
+		return "", errBSONShort
+	}
+	s := string(p.data[p.pos : p.pos+end])
+	p.pos += end + 1
+	return s, nil
+}
+
+func (p *bsonParser) str() (string, error) {
+	n, err := p.int32()
+	if err != nil {
+		return "", err
+	}
+	b, err := p.next(int(n))
+	if err != nil || n < 1 {
+		return "", errBSONShort
+	}
+	return string(b[:n-1]), nil
+}
+
+// document reads an embedded document, starting with its size
+func (p *bsonParser) document() (map[string]interface{}, error) {
+	size, err := p.int32()
+	if err != nil {
+		return nil, err
+	}
+	end := p.pos - 4 + int(size)
+	if size < 5 || end > len(p.data) {
+		return nil, errBSONShort
+	}
+	doc := make(map[string]interface{})
+	for p.pos < end-1 {
+		tp := p.data[p.pos]
+		p.pos++
+		key, err := p.cstring()
+		if err != nil {
+			return nil, err
+		}
+		val, err := p.value(tp)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", key, err)
+		}
+		doc[key] = val
+	}
+	if p.pos != end-1 || p.data[p.pos] != 0 {
+		return nil, errors.New("BSON document is not terminated")
+	}
+	p.pos = end
+	return doc, nil
+}
+
+// array reads an embedded array, a document with the keys "0", "1", ...
+func (p *bsonParser) array() ([]interface{}, error) {
+	doc, err := p.document()
+	if err != nil {
+		return nil, err
+	}
+	arr := make([]interface{}, len(doc))
+	for i := range arr {
+		val, ok := doc[strconv.Itoa(i)]
+		if !ok {
+			return nil, errors.New("BSON array has missing indexes")
+		}
+		arr[i] = val
+	}
+	return arr, nil
+}
+
+// value reads the value of an element of type tp
+func (p *bsonParser) value(tp byte) (interface{}, error) {
+	switch tp {
+	case bsonDouble:
+		b, err := p.next(8)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(binary.LittleEndian.Uint64(b)), nil
+	case bsonString, bsonJavaScript, bsonSymbol:
+		return p.str()
+	case bsonDocument:
+		return p.document()
+	case bsonArray:
+		return p.array()
+	case bsonBinary:
+		n, err := p.int32()
+		if err != nil {
+			return nil, err
+		}
+		if n < 0 {
+			return nil, fmt.Errorf("Invalid binary length %d", n)
+		}
+		// The subtype byte comes before the n bytes of data
+		b, err := p.next(int(n) + 1)
+		if err != nil {
+			return nil, err
+		}
+		return append([]byte{}, b[1:]...), nil
+	case bsonUndefined, bsonNull, bsonMinKey, bsonMaxKey:
+		return nil, nil
+	case bsonObjectID:
+		b, err := p.next(12)
+		if err != nil {
+			return nil, err
+		}
+		var oid objectID
+		copy(oid[:], b)
+		return oid, nil
+	case bsonBool:
+		b, err := p.next(1)
+		if err != nil {
+			return nil, err
+		}
+		return b[0] == 1, nil
+	case bsonDateTime:
+		ms, err := p.int64()
+		if err != nil {
+			return nil, err
+		}
+		return bsonTime(ms), nil
+	case bsonRegexp:
+		pattern, err := p.cstring()
+		if err != nil {
+			return nil, err
+		}
+		options, err := p.cstring()
+		return bsonRegex{pattern, options}, err
+	case bsonDBPointer:
+		if _, err := p.str(); err != nil {
+			return nil, err
+		}
+		b, err := p.next(12)
+		if err != nil {
+			return nil, err
+		}
+		var oid objectID
+		copy(oid[:], b)
+		return oid, nil
+	case bsonCodeScope:
+		if _, err := p.int32(); err != nil {
+			return nil, err
+		}
+		code, err := p.str()
+		if err != nil {
+			return nil, err
+		}
+		_, err = p.document()
+		return code, err
+	case bsonInt32:
+		i, err := p.int32()
+		return int(i), err
+	case bsonTimestampT:
+		b, err := p.next(8)
+		if err != nil {
+			return nil, err
+		}
+		return bsonTimestamp{binary.LittleEndian.Uint32(b[4:]), binary.LittleEndian.Uint32(b)}, nil
+	case bsonInt64:
+		return p.int64()
+	case bsonDecimal128:
+		b, err := p.next(16)
+		if err != nil {
+			return nil, err
+		}
+		var d decimal128
+		copy(d[:], b)
+		return d, nil
+	default:
+		return nil, fmt.Errorf("Unknown BSON element type 0x%02x", tp)
+	}
+}
+
+// ExtJSON type structure to convert MongoDB Extended JSON, canonical or
+// relaxed, to go struct. The type wrappers, like {"$oid": ...} or
+// {"$date": ...}, are unwrapped into the type they stand for. Every
+// document of the input, e.g. of a mongoexport file, is a sample of the
+// root type.
+type ExtJSON struct {
+	File   string
+	reader io.Reader
+}
+
+// NewExtJSONReader creates an ExtJSON decoder reading from r instead of a
+// file. The reader is consumed by the first Decode.
+func NewExtJSONReader(r io.Reader) *ExtJSON {
+	return &ExtJSON{reader: r}
+}
+
+// NewExtJSONBytes creates an ExtJSON decoder for the in-memory data
+func NewExtJSONBytes(b []byte) *ExtJSON {
+	return NewExtJSONReader(bytes.NewReader(b))
+}
+
+// Decode this ExtJSON instance into decodedData
+func (e *ExtJSON) Decode() (DecodedData, error) {
+
+	dd := new(DecodedData)
+	f, err := open(e.File, e.reader)
+	if err != nil {
+		log.Println("Error while reading file", err)
+		return *dd, err
+	}
+	defer f.Close()
+
+	dec := json.NewDecoder(f)
+	dec.UseNumber()
+	for {
+		var val interface{}
+		err := dec.Decode(&val)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Println("Error while decoding", err)
+			return *dd, err
+		}
+		val, err = unwrapExtJSON(val)
+		if err != nil {
+			log.Println("Error while unwrapping", err)
+			return *dd, err
+		}
+		doc, err := toDecodedData(val)
+		if err != nil {
+			return *dd, err
+		}
+		dd.documents = append(dd.documents, doc)
+	}
+	if len(dd.documents) == 0 {
+		return *dd, io.ErrUnexpectedEOF
+	}
+	if len(dd.documents) == 1 {
+		return dd.documents[0], nil
+	}
+	return *dd, nil
+}
+
+// Source of this ExtJSON instance, the file name or empty if read from a reader
+func (e *ExtJSON) Source() string {
+	return e.File
+}
+
+// Annotate a field name with its bson tag
+func (e *ExtJSON) Annotate(name string) string {
+	return "bson:" + name
+}
+
+// unwrapExtJSON replaces the type wrappers of Extended JSON by values of the
+// types they stand for. Plain numbers become int, int64 or float64.
+func unwrapExtJSON(val interface{}) (interface{}, error) {
+	switch v := val.(type) {
+	case json.Number:
+		return scalarValue(v.String()), nil
+	case []interface{}:
+		for i, e := range v {
+			uv, err := unwrapExtJSON(e)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = uv
+		}
+		return v, nil
+	case map[string]interface{}:
+		if uv, ok, err := unwrapExtJSONType(v); ok || err != nil {
+			return uv, err
+		}
+		for k, e := range v {
+			uv, err := unwrapExtJSON(e)
+			if err != nil {
+				return nil, err
+			}
+			v[k] = uv
+		}
+		return v, nil
+	default:
+		return v, nil
+	}
+}
+
+// unwrapExtJSONType returns the value the object stands for if the object
+// is one of the type wrappers of Extended JSON
+func unwrapExtJSONType(obj map[string]interface{}) (interface{}, bool, error) {
+	str := func(key string) (string, error) {
+		s, ok := obj[key].(string)
+		if !ok {
+			return "", fmt.Errorf("Expected a string in %s but found %v", key, obj[key])
+		}
+		return s, nil
+	}
+
+	switch {
+	case len(obj) == 1 && obj["$oid"] != nil:
+		s, err := str("$oid")
+		if err != nil {
+			return nil, true, err
+		}
+		var oid objectID
+		b, err := hex.DecodeString(s)
+		if err != nil || len(b) != len(oid) {
+			return nil, true, fmt.Errorf("Invalid $oid %s", s)
+		}
+		copy(oid[:], b)
+		return oid, true, nil
+	case len(obj) == 1 && obj["$date"] != nil:
+		t, err := extJSONDate(obj["$date"])
+		return t, true, err
+	case len(obj) == 1 && obj["$numberLong"] != nil:
+		s, err := str("$numberLong")
+		if err != nil {
+			return nil, true, err
+		}
+		i, err := strconv.ParseInt(s, 10, 64)
+		return i, true, err
+	case len(obj) == 1 && obj["$numberInt"] != nil:
+		s, err := str("$numberInt")
+		if err != nil {
+			return nil, true, err
+		}
+		i, err := strconv.ParseInt(s, 10, 32)
+		return int(i), true, err
+	case len(obj) == 1 && obj["$numberDouble"] != nil:
+		s, err := str("$numberDouble")
+		if err != nil {
+			return nil, true, err
+		}
+		f, err := strconv.ParseFloat(s, 64)
+		return f, true, err
+	case len(obj) == 1 && obj["$numberDecimal"] != nil:
+		_, err := str("$numberDecimal")
+		return decimal128{}, true, err
+	case obj["$binary"] != nil && (len(obj) == 1 || (len(obj) == 2 && obj["$type"] != nil)):
+		b64, ok := obj["$binary"].(string)
+		if bin, isObj := obj["$binary"].(map[string]interface{}); isObj {
+			b64, ok = bin["base64"].(string)
+		}
+		if !ok {
+			return nil, true, fmt.Errorf("Expected base64 data in $binary but found %v", obj["$binary"])
+		}
+		b, err := base64.StdEncoding.DecodeString(b64)
+		return b, true, err
+	case len(obj) == 1 && obj["$uuid"] != nil:
+		_, err := str("$uuid")
+		return []byte{}, true, err
+	case len(obj) == 1 && obj["$timestamp"] != nil:
+		return bsonTimestamp{}, true, nil
+	case len(obj) == 1 && obj["$regularExpression"] != nil,
+		len(obj) == 2 && obj["$regex"] != nil && obj["$options"] != nil:
+		return bsonRegex{}, true, nil
+	case len(obj) == 1 && obj["$symbol"] != nil:
+		s, err := str("$symbol")
+		return s, true, err
+	case obj["$code"] != nil && (len(obj) == 1 || (len(obj) == 2 && obj["$scope"] != nil)):
+		s, err := str("$code")
+		return s, true, err
+	case len(obj) == 1 && (obj["$minKey"] != nil || obj["$maxKey"] != nil || obj["$undefined"] != nil):
+		return nil, true, nil
+	}
+	return nil, false, nil
+}
+
+// extJSONDate converts the value of $date: an ISO-8601 string in relaxed
+// mode, {"$numberLong": "<millis>"} in canonical mode or plain millis.
+func extJSONDate(val interface{}) (time.Time, error) {
+	var ms int64
+	switch v := val.(type) {
+	case string:
+		return time.Parse(time.RFC3339Nano, v)
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil {
+			return time.Time{}, err
+		}
+		ms = int64(f)
+	case map[string]interface{}:
+		s, ok := v["$numberLong"].(string)
+		if !ok || len(v) != 1 {
+			return time.Time{}, fmt.Errorf("Unknown $date %v", v)
+		}
+		var err error
+		if ms, err = strconv.ParseInt(s, 10, 64); err != nil {
+			return time.Time{}, err
+		}
+	default:
+		return time.Time{}, fmt.Errorf("Unknown $date %v", v)
+	}
+	return bsonTime(ms), nil
+}
+
+// bsonTime returns the time of a BSON date, given in milliseconds since the
+// epoch. Dates are kept in seconds as milliseconds overflow the nanoseconds of
+// time.Unix after the year 2262.
+func bsonTime(ms int64) time.Time {
+	return time.Unix(ms/1000, ms%1000*int64(time.Millisecond)).UTC()
+}
@@ -0,0 +1,229 @@
+package togo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+// bsonElem encodes an element of a BSON document for the tests
+func bsonElem(tp byte, key string, val []byte) []byte {
+	b := append([]byte{tp}, key...)
+	b = append(b, 0)
+	return append(b, val...)
+}
+
+// bsonDoc encodes a BSON document of the elements for the tests
+func bsonDoc(elems ...[]byte) []byte {
+	body := bytes.Join(elems, nil)
+	b := make([]byte, 4, len(body)+5)
+	binary.LittleEndian.PutUint32(b, uint32(len(body)+5))
+	b = append(b, body...)
+	return append(b, 0)
+}
+
+func bsonLE(v interface{}) []byte {
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, v)
+	return buf.Bytes()
+}
+
+func bsonStr(s string) []byte {
+	return append(append(bsonLE(int32(len(s)+1)), s...), 0)
+}
+
+func TestBSON_Decode(t *testing.T) {
+	doc := bsonDoc(
+		bsonElem(bsonObjectID, "_id", bytes.Repeat([]byte{0xab}, 12)),
+		bsonElem(bsonString, "name", bsonStr("togo")),
+		bsonElem(bsonInt32, "age", bsonLE(int32(36))),
+		bsonElem(bsonInt64, "visits", bsonLE(int64(12))),
+		bsonElem(bsonDouble, "score", bsonLE(math.Float64bits(0.5))),
+		bsonElem(bsonBool, "active", []byte{1}),
+		bsonElem(bsonDateTime, "created", bsonLE(int64(1596240000000))),
+		bsonElem(bsonNull, "deleted", nil),
+		bsonElem(bsonBinary, "avatar", append(append(bsonLE(int32(2)), 0), 0xca, 0xfe)),
+		bsonElem(bsonDecimal128, "balance", make([]byte, 16)),
+		bsonElem(bsonTimestampT, "ts", bsonLE(uint64(1))),
+		bsonElem(bsonRegexp, "pattern", []byte("^a\x00i\x00")),
+		bsonElem(bsonDocument, "address", bsonDoc(bsonElem(bsonString, "city", bsonStr("Pune")))),
+		bsonElem(bsonArray, "tags", bsonDoc(
+			bsonElem(bsonString, "0", bsonStr("a")), bsonElem(bsonString, "1", bsonStr("b")))),
+	)
+	dd, err := NewBSONBytes(append(doc, doc...)).Decode()
+	if err != nil {
+		t.Fatalf("Decode failed: %+v", err)
+	}
+	if len(dd.documents) != 2 {
+		t.Fatalf("Expected 2 documents but got %d", len(dd.documents))
+	}
+	mp := dd.documents[0].mapData
+	tests := []struct {
+		tc  string
+		key string
+		dt  FieldDT
+		tn  string
+	}{
+		{"ObjectID", "_id", Named, "primitive.ObjectID"},
+		{"String", "name", String, ""},
+		{"Int32", "age", Int, ""},
+		{"Int64", "visits", Int64, ""},
+		{"Double", "score", Float64, ""},
+		{"Bool", "active", Bool, ""},
+		{"DateTime", "created", Named, "time.Time"},
+		{"Null", "deleted", Interface, ""},
+		{"Binary", "avatar", Named, "[]byte"},
+		{"Decimal128", "balance", Named, "primitive.Decimal128"},
+		{"Timestamp", "ts", Named, "primitive.Timestamp"},
+		{"Regex", "pattern", Named, "primitive.Regex"},
+		{"Document", "address", Map, ""},
+		{"Array", "tags", Slice, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tc, func(t *testing.T) {
+			f, err := ToField(tt.key, mp[tt.key])
+			if err != nil {
+				t.Fatalf("TC: %s: ToField failed: %+v", tt.tc, err)
+			}
+			if f.dataType != tt.dt || f.dtStruct != tt.tn {
+				t.Errorf("TC: %s: Expected (%v, %s) but got (%v, %s)", tt.tc,
+					tt.dt.str(), tt.tn, f.dataType.str(), f.dtStruct)
+			}
+		})
+	}
+	if !mp["created"].(time.Time).Equal(time.Date(2020, 8, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected created 2020-08-01 but got %v", mp["created"])
+	}
+	if tags := mp["tags"].([]interface{}); len(tags) != 2 || tags[1] != "b" {
+		t.Errorf("Expected tags [a b] but got %v", tags)
+	}
+}
+
+func TestBSON_DecodeInvalid(t *testing.T) {
+	valid := bsonDoc(bsonElem(bsonString, "name", bsonStr("togo")))
+	tests := []struct {
+		tc   string
+		data []byte
+	}{
+		{"Empty", nil},
+		{"Truncated", valid[:len(valid)-3]},
+		{"Not Terminated", append(valid[:len(valid)-1], 1)},
+		{"Unknown Type", bsonDoc(bsonElem(0x42, "x", nil))},
+		{"Negative Binary Length", bsonDoc(bsonElem(bsonBinary, "b", append(bsonLE(int32(-1)), 0)))},
+		{"Binary Past The End", bsonDoc(bsonElem(bsonBinary, "b", append(bsonLE(int32(10)), 0, 1)))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tc, func(t *testing.T) {
+			if _, err := NewBSONBytes(tt.data).Decode(); err == nil {
+				t.Errorf("TC: %s: Expected error but did not get any error", tt.tc)
+			}
+		})
+	}
+}
+
+func TestBSON_DecodeFarDate(t *testing.T) {
+	exp := time.Date(2500, 1, 2, 3, 4, 5, 6e6, time.UTC)
+	ms := exp.Unix()*1000 + 6
+	dd, err := NewBSONBytes(bsonDoc(bsonElem(bsonDateTime, "d", bsonLE(ms)))).Decode()
+	if err != nil {
+		t.Fatalf("Decode failed: %+v", err)
+	}
+	if got := dd.mapData["d"].(time.Time); !got.Equal(exp) {
+		t.Errorf("Expected %v but got %v", exp, got)
+	}
+	dd, err = NewExtJSONBytes([]byte(fmt.Sprintf(`{"d": {"$date": {"$numberLong": "%d"}}}`, ms))).Decode()
+	if err != nil {
+		t.Fatalf("Decode failed: %+v", err)
+	}
+	if got := dd.mapData["d"].(time.Time); !got.Equal(exp) {
+		t.Errorf("Expected %v but got %v", exp, got)
+	}
+}
+
+func TestExtJSON_Decode(t *testing.T) {
+	tests := []struct {
+		tc     string
+		data   string
+		dt     FieldDT
+		tn     string
+		expErr bool
+	}{
+		{"ObjectID", `{"v": {"$oid": "5f2a1b3c4d5e6f7a8b9c0d1e"}}`, Named, "primitive.ObjectID", false},
+		{"Relaxed Date", `{"v": {"$date": "2020-08-01T00:00:00.000Z"}}`, Named, "time.Time", false},
+		{"Canonical Date", `{"v": {"$date": {"$numberLong": "1596240000000"}}}`, Named, "time.Time", false},
+		{"Legacy Date", `{"v": {"$date": 1596240000000}}`, Named, "time.Time", false},
+		{"Number Long", `{"v": {"$numberLong": "5"}}`, Int64, "", false},
+		{"Number Int", `{"v": {"$numberInt": "5"}}`, Int, "", false},
+		{"Number Double", `{"v": {"$numberDouble": "Infinity"}}`, Float64, "", false},
+		{"Number Decimal", `{"v": {"$numberDecimal": "1.5"}}`, Named, "primitive.Decimal128", false},
+		{"Binary", `{"v": {"$binary": {"base64": "AQI=", "subType": "00"}}}`, Named, "[]byte", false},
+		{"Legacy Binary", `{"v": {"$binary": "AQI=", "$type": "00"}}`, Named, "[]byte", false},
+		{"Timestamp", `{"v": {"$timestamp": {"t": 1, "i": 2}}}`, Named, "primitive.Timestamp", false},
+		{"Regular Expression", `{"v": {"$regularExpression": {"pattern": "a", "options": ""}}}`,
+			Named, "primitive.Regex", false},
+		{"Relaxed Int", `{"v": 5}`, Int, "", false},
+		{"Relaxed Float", `{"v": 5.5}`, Float64, "", false},
+		{"Not A Wrapper", `{"v": {"$oid": "x", "other": 1}}`, Map, "", false},
+		{"Invalid ObjectID", `{"v": {"$oid": "xyz"}}`, Initial, "", true},
+		{"Invalid Number Long", `{"v": {"$numberLong": 5}}`, Initial, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tc, func(t *testing.T) {
+			dd, err := NewExtJSONBytes([]byte(tt.data)).Decode()
+			if tt.expErr {
+				if err == nil {
+					t.Errorf("TC: %s: Expected error but did not get any error", tt.tc)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("TC: %s: Did not expect error but got %+v", tt.tc, err)
+			}
+			f, err := ToField("v", dd.mapData["v"])
+			if err != nil {
+				t.Fatalf("TC: %s: ToField failed: %+v", tt.tc, err)
+			}
+			if f.dataType != tt.dt || f.dtStruct != tt.tn {
+				t.Errorf("TC: %s: Expected (%v, %s) but got (%v, %s)", tt.tc,
+					tt.dt.str(), tt.tn, f.dataType.str(), f.dtStruct)
+			}
+		})
+	}
+}
+
+func TestParse_ExtJSON(t *testing.T) {
+	dec, err := NewFileDecoder(FormatExtJSON, "samples/extjson/users.json")
+	if err != nil {
+		t.Fatalf("NewFileDecoder failed: %+v", err)
+	}
+	if err = Parse(dec); err != nil {
+		t.Fatalf("Parse failed: %+v", err)
+	}
+	var buf bytes.Buffer
+	if err = WriteStructs(&buf); err != nil {
+		t.Fatalf("WriteStructs failed: %+v", err)
+	}
+	out := buf.String()
+	for _, exp := range []string{
+		"import (\n\t\"go.mongodb.org/mongo-driver/bson/primitive\"\n\t\"time\"\n)\n",
+		"\tID primitive.ObjectID `bson:\"_id\"`",
+		"\tAge int `bson:\"age\"`",
+		"\tVisits int64 `bson:\"visits\"`",
+		"\tBalance primitive.Decimal128 `bson:\"balance\"`",
+		"\tCreated time.Time `bson:\"created\"`",
+		"\tAvatar []byte `bson:\"avatar\"`",
+		"\tAddress Address `bson:\"address\"`",
+		"\tCoordinates []float64 `bson:\"coordinates\"`",
+	} {
+		if !strings.Contains(out, exp) {
+			t.Errorf("Expected %q in the structs but got:\n%s", exp, out)
+		}
+	}
+	if strings.Contains(out, "$") {
+		t.Errorf("Expected no wrapper to become a struct but got:\n%s", out)
+	}
+}
@@ -0,0 +1,182 @@
+package togo
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"math/big"
+	"strconv"
+	"time"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+// Semantic tags of CBOR understood by the CBOR decoder, besides the date/time
+// tags 0 and 1 which the cbor package decodes into time.Time already.
+const (
+	cborTagPosBignum     = 2
+	cborTagNegBignum     = 3
+	cborTagDecimal       = 4
+	cborTagBigfloat      = 5
+	cborTagSelfDescribed = 55799
+)
+
+// CBOR type structure to convert CBOR to go struct. Date/times (tags 0 and 1)
+// become time.Time fields, bignums (tags 2 and 3) *big.Int fields, decimal
+// fractions and bigfloats (tags 4 and 5) float64 fields and byte strings
+// []byte fields. Other tags are looked through. A stream holding several
+// items one after another is decoded as that many samples of the root type.
+type CBOR struct {
+	File   string
+	reader io.Reader
+}
+
+// NewCBORReader creates a CBOR decoder reading from r instead of a file.
+// The reader is consumed by the first Decode.
+func NewCBORReader(r io.Reader) *CBOR {
+	return &CBOR{reader: r}
+}
+
+// NewCBORBytes creates a CBOR decoder for the in-memory data
+func NewCBORBytes(b []byte) *CBOR {
+	return NewCBORReader(bytes.NewReader(b))
+}
+
+// Decode this CBOR instance into decodedData
+func (c *CBOR) Decode() (DecodedData, error) {
+
+	dd := new(DecodedData)
+	f, err := open(c.File, c.reader)
+	if err != nil {
+		log.Println("Error while reading file", err)
+		return *dd, err
+	}
+	defer f.Close()
+
+	dec := cbor.NewDecoder(f)
+	for {
+		var val interface{}
+		err := dec.Decode(&val)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Println("Error while decoding", err)
+			return *dd, err
+		}
+		doc, err := toDecodedData(normalizeCBOR(val))
+		if err != nil {
+			return *dd, err
+		}
+		dd.documents = append(dd.documents, doc)
+	}
+	if len(dd.documents) == 0 {
+		return *dd, io.ErrUnexpectedEOF
+	}
+	if len(dd.documents) == 1 {
+		return dd.documents[0], nil
+	}
+	return *dd, nil
+}
+
+// normalizeCBOR converts the values decoded by cbor into the types
+// understood by ToField. CBOR does not tell the size of integers, so they
+// become int if they fit in 32 bits, int64 if they fit in 64 bits and uint64
+// otherwise. Maps get string keys and the tags are replaced by their values.
+func normalizeCBOR(val interface{}) interface{} {
+	switch v := val.(type) {
+	case uint64:
+		if v <= math.MaxInt32 {
+			return int(v)
+		} else if v <= math.MaxInt64 {
+			return int64(v)
+		}
+		return v
+	case int64:
+		if v >= math.MinInt32 {
+			return int(v)
+		}
+		return v
+	case float32:
+		return float64(v)
+	case time.Time:
+		return v.UTC()
+	case cbor.Tag:
+		return cborTag(v)
+	case map[interface{}]interface{}:
+		mp := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			mp[fmt.Sprint(k)] = normalizeCBOR(e)
+		}
+		return mp
+	case []interface{}:
+		for i, e := range v {
+			v[i] = normalizeCBOR(e)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// cborTag returns the value of the tagged item
+func cborTag(t cbor.Tag) interface{} {
+	switch t.Number {
+	case cborTagPosBignum, cborTagNegBignum:
+		b, ok := t.Content.([]byte)
+		if !ok {
+			break
+		}
+		n := new(big.Int).SetBytes(b)
+		if t.Number == cborTagNegBignum {
+			n.Neg(n).Sub(n, big.NewInt(1))
+		}
+		return n
+	case cborTagDecimal, cborTagBigfloat:
+		if f, ok := cborFraction(t); ok {
+			return f
+		}
+	case cborTagSelfDescribed:
+	default:
+		log.Printf("Looking through unknown CBOR tag %d\n", t.Number)
+	}
+	return normalizeCBOR(t.Content)
+}
+
+// cborFraction returns the float64 value of a decimal fraction or bigfloat,
+// an array of an exponent and a mantissa
+func cborFraction(t cbor.Tag) (float64, bool) {
+	arr, ok := t.Content.([]interface{})
+	if !ok || len(arr) != 2 {
+		return 0, false
+	}
+	exp, ok := normalizeCBOR(arr[0]).(int)
+	if !ok {
+		return 0, false
+	}
+	var mant string
+	switch m := normalizeCBOR(arr[1]).(type) {
+	case int, int64, uint64, *big.Int:
+		mant = fmt.Sprint(m)
+	default:
+		return 0, false
+	}
+	if t.Number == cborTagBigfloat {
+		f, _ := strconv.ParseFloat(mant, 64)
+		return math.Ldexp(f, exp), true
+	}
+	f, err := strconv.ParseFloat(fmt.Sprintf("%se%d", mant, exp), 64)
+	return f, err == nil
+}
+
+// Source of this CBOR instance, the file name or empty if read from a reader
+func (c *CBOR) Source() string {
+	return c.File
+}
+
+// Annotate a field name with its cbor tag
+func (c *CBOR) Annotate(name string) string {
+	return "cbor:" + name
+}
@@ -0,0 +1,111 @@
+package togo
+
+import (
+	"bytes"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+func TestCBOR_Decode(t *testing.T) {
+	b, err := cbor.Marshal(map[interface{}]interface{}{
+		"small":   -3,
+		"big":     uint64(1) << 40,
+		"huge":    uint64(1) << 63,
+		"ratio":   0.5,
+		"blob":    []byte("raw"),
+		"name":    "togo",
+		"epoch":   cbor.Tag{Number: 1, Content: 1596240000},
+		"rfc3339": cbor.Tag{Number: 0, Content: "2020-08-01T00:00:00Z"},
+		"bignum":  cbor.Tag{Number: 2, Content: []byte{0x01, 0, 0, 0, 0, 0, 0, 0, 0}},
+		"negnum":  cbor.Tag{Number: 3, Content: []byte{0x01, 0, 0, 0, 0, 0, 0, 0, 0}},
+		"decimal": cbor.Tag{Number: 4, Content: []interface{}{-2, 27315}},
+		"uri":     cbor.Tag{Number: 32, Content: "https://example.com"},
+		1:         "integer key",
+	})
+	if err != nil {
+		t.Fatalf("Marshal failed: %+v", err)
+	}
+	dd, err := NewCBORBytes(b).Decode()
+	if err != nil {
+		t.Fatalf("Decode failed: %+v", err)
+	}
+	tests := []struct {
+		tc  string
+		key string
+		dt  FieldDT
+		tn  string
+	}{
+		{"Small Int", "small", Int, ""},
+		{"Big Int", "big", Int64, ""},
+		{"Huge Int", "huge", Named, "uint64"},
+		{"Float", "ratio", Float64, ""},
+		{"Byte String", "blob", Named, "[]byte"},
+		{"Text String", "name", String, ""},
+		{"Epoch Time", "epoch", Named, "time.Time"},
+		{"RFC3339 Time", "rfc3339", Named, "time.Time"},
+		{"Bignum", "bignum", Named, "*big.Int"},
+		{"Negative Bignum", "negnum", Named, "*big.Int"},
+		{"Decimal Fraction", "decimal", Float64, ""},
+		{"Unknown Tag", "uri", String, ""},
+		{"Integer Key", "1", String, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tc, func(t *testing.T) {
+			f, err := ToField(tt.key, dd.mapData[tt.key])
+			if err != nil {
+				t.Fatalf("TC: %s: ToField failed: %+v", tt.tc, err)
+			}
+			if f.dataType != tt.dt || f.dtStruct != tt.tn {
+				t.Errorf("TC: %s: Expected (%v, %s) but got (%v, %s)", tt.tc,
+					tt.dt.str(), tt.tn, f.dataType.str(), f.dtStruct)
+			}
+		})
+	}
+
+	exp := new(big.Int).Lsh(big.NewInt(1), 64)
+	if dd.mapData["bignum"].(*big.Int).Cmp(exp) != 0 {
+		t.Errorf("Expected bignum %v but got %v", exp, dd.mapData["bignum"])
+	}
+	exp.Neg(exp).Sub(exp, big.NewInt(1))
+	if dd.mapData["negnum"].(*big.Int).Cmp(exp) != 0 {
+		t.Errorf("Expected negative bignum %v but got %v", exp, dd.mapData["negnum"])
+	}
+	if dd.mapData["decimal"] != 273.15 {
+		t.Errorf("Expected decimal fraction 273.15 but got %v", dd.mapData["decimal"])
+	}
+	if !dd.mapData["epoch"].(time.Time).Equal(time.Date(2020, 8, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected epoch time 2020-08-01 but got %v", dd.mapData["epoch"])
+	}
+}
+
+func TestParse_CBOR(t *testing.T) {
+	dec, err := NewFileDecoder("", "samples/cbor/frames.cbor")
+	if err != nil {
+		t.Fatalf("NewFileDecoder failed: %+v", err)
+	}
+	if err = Parse(dec); err != nil {
+		t.Fatalf("Parse failed: %+v", err)
+	}
+	var buf bytes.Buffer
+	if err = WriteStructs(&buf); err != nil {
+		t.Fatalf("WriteStructs failed: %+v", err)
+	}
+	out := buf.String()
+	for _, exp := range []string{
+		"\tAt time.Time `cbor:\"at\"`",
+		"\tBoot time.Time `cbor:\"boot\"`",
+		"\tEnergy *big.Int `cbor:\"energy\"`",
+		"\tRaw []byte `cbor:\"raw\"`",
+		"\tBattery float64 `cbor:\"battery\"`",
+		"\tReadings []Readings `cbor:\"readings\"`",
+		"\tMv int `cbor:\"mv\"`",
+	} {
+		if !strings.Contains(out, exp) {
+			t.Errorf("Expected %q in the structs but got:\n%s", exp, out)
+		}
+	}
+}
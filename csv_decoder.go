@@ -0,0 +1,137 @@
+package togo
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+)
+
+// CSV type structure to convert to go struct. Every record of the file is a
+// sample of a single row struct whose field types are inferred per column.
+// An empty cell in a column makes the field optional.
+type CSV struct {
+	File   string
+	reader io.Reader
+	// Delimiter separating the cells, ',' if not set. Use '\t' for TSV.
+	Delimiter rune
+	// NoHeader is set if the first record is data rather than column names,
+	// in which case the columns are named Column1, Column2, ... Otherwise the
+	// column names must be unique.
+	NoHeader bool
+	// SampleRows is the number of data rows read for inference, all if not set.
+	SampleRows int
+	optional   map[string]bool
+}
+
+// NewCSVReader creates a CSV decoder reading from r instead of a file.
+// The reader is consumed by the first Decode.
+func NewCSVReader(r io.Reader) *CSV {
+	return &CSV{reader: r}
+}
+
+// NewCSVBytes creates a CSV decoder for the in-memory data
+func NewCSVBytes(b []byte) *CSV {
+	return NewCSVReader(bytes.NewReader(b))
+}
+
+// Decode this CSV instance into decodedData
+func (c *CSV) Decode() (DecodedData, error) {
+
+	dd := new(DecodedData)
+	f, err := open(c.File, c.reader)
+	if err != nil {
+		log.Println("Error while reading file", err)
+		return *dd, err
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	if c.Delimiter != 0 {
+		r.Comma = c.Delimiter
+	}
+	r.FieldsPerRecord = -1
+
+	var header []string
+	var rows [][]string
+	for c.SampleRows <= 0 || len(rows) < c.SampleRows {
+		rec, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Println("Error while decoding", err)
+			return *dd, err
+		}
+		if header == nil && !c.NoHeader {
+			header = make([]string, len(rec))
+			seen := make(map[string]bool, len(rec))
+			for i, h := range rec {
+				header[i] = strings.TrimSpace(h)
+				if seen[header[i]] {
+					return *dd, fmt.Errorf("Column %q appears more than once in the header", header[i])
+				}
+				seen[header[i]] = true
+			}
+			continue
+		}
+		rows = append(rows, rec)
+	}
+	if header == nil {
+		for _, row := range rows {
+			for i := len(header); i < len(row); i++ {
+				header = append(header, fmt.Sprintf("Column%d", i+1))
+			}
+		}
+	}
+
+	types := make([]FieldDT, len(header))
+	c.optional = make(map[string]bool)
+	for i, h := range header {
+		for _, row := range rows {
+			if i >= len(row) || row[i] == "" {
+				c.optional[h] = true
+				continue
+			}
+			types[i] = widen(types[i], inferScalar(row[i]))
+		}
+		if types[i] == Initial {
+			types[i] = String
+		}
+	}
+	log.Printf("Inferred column types %v for columns %v\n", types, header)
+
+	if len(rows) == 0 {
+		rows = append(rows, make([]string, len(header)))
+		for i := range header {
+			types[i] = String
+		}
+	}
+	for _, row := range rows {
+		mp := make(map[string]interface{}, len(header))
+		for i, h := range header {
+			if i >= len(row) || (row[i] == "" && types[i] != String) {
+				mp[h] = nil
+				continue
+			}
+			mp[h] = convertScalar(row[i], types[i])
+		}
+		dd.documents = append(dd.documents, DecodedData{mapData: mp})
+	}
+	return *dd, nil
+}
+
+// Source of this CSV instance, the file name or empty if read from a reader
+func (c *CSV) Source() string {
+	return c.File
+}
+
+// Annotate a field name with its csv tag
+func (c *CSV) Annotate(name string) string {
+	if c.optional[name] {
+		return "csv:" + name + ",omitempty"
+	}
+	return "csv:" + name
+}
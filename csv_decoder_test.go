@@ -0,0 +1,78 @@
+package togo
+
+import (
+	"testing"
+)
+
+func TestCSV_Decode(t *testing.T) {
+	tests := []struct {
+		tc     string
+		csv    *CSV
+		column string
+		val    interface{}
+		tag    string
+	}{
+		{"Int Column", &CSV{File: "samples/csv/users.csv"}, "id", 1, "csv:id"},
+		{"Bool Column", &CSV{File: "samples/csv/users.csv"}, "active", true, "csv:active"},
+		{"Float Column", &CSV{File: "samples/csv/users.csv"}, "score", 9.5, "csv:score"},
+		{"Int64 Column", &CSV{File: "samples/csv/users.csv"}, "balance", int64(12000000000), "csv:balance,omitempty"},
+		{"Leading Zero Column", &CSV{File: "samples/csv/users.csv"}, "zip", "02139", "csv:zip"},
+		{"Optional Column", &CSV{File: "samples/csv/users.csv"}, "joined", "2019-04-01", "csv:joined,omitempty"},
+		{"Sampled Rows", &CSV{File: "samples/csv/users.csv", SampleRows: 1}, "balance", int64(12000000000), "csv:balance"},
+		{"No Header", &CSV{File: "samples/csv/users.csv", NoHeader: true}, "Column1", "id", "csv:Column1"},
+		{"Tab Delimiter", &CSV{File: "samples/csv/cities.tsv", Delimiter: '\t'}, "city", "Paris", "csv:city"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tc, func(t *testing.T) {
+			dd, err := tt.csv.Decode()
+			if err != nil {
+				t.Fatalf("TC: %s: Did not expect error but got %#v", tt.tc, err)
+			}
+			if len(dd.documents) == 0 {
+				t.Fatalf("TC: %s: No rows decoded", tt.tc)
+			}
+			val := dd.documents[0].mapData[tt.column]
+			if val != tt.val {
+				t.Errorf("TC: %s: Expected %#v but got %#v", tt.tc, tt.val, val)
+			}
+			if ann := tt.csv.Annotate(tt.column); ann != tt.tag {
+				t.Errorf("TC: %s: Expected annotation %s but got %s", tt.tc, tt.tag, ann)
+			}
+		})
+	}
+}
+
+func Test_inferScalar(t *testing.T) {
+	tests := []struct {
+		tc  string
+		val string
+		dt  FieldDT
+	}{
+		{"Bool", "False", Bool},
+		{"Int", "42", Int},
+		{"Negative Int", "-7", Int},
+		{"Int64", "9000000000", Int64},
+		{"Float", "3.14", Float64},
+		{"Leading Zero", "007", String},
+		{"Zero Float", "0.5", Float64},
+		{"Not A Number", "NaN", String},
+		{"Signed Infinity", "+Inf", String},
+		{"Negative Infinity", "-Infinity", String},
+		{"Signed Not A Number", "-nan", String},
+		{"Exponent", "-1.5e3", Float64},
+		{"String", "hello", String},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tc, func(t *testing.T) {
+			if dt := inferScalar(tt.val); dt != tt.dt {
+				t.Errorf("TC: %s: Expected %v but got %v", tt.tc, tt.dt, dt)
+			}
+		})
+	}
+}
+
+func TestCSV_DecodeDuplicateHeader(t *testing.T) {
+	if _, err := NewCSVBytes([]byte("id,name,id\n1,a,2\n")).Decode(); err == nil {
+		t.Errorf("Expected error for the duplicate column but did not get any error")
+	}
+}
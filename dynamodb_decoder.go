@@ -0,0 +1,212 @@
+package togo
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+)
+
+// DynamoDB type structure to convert DynamoDB's typed JSON to go struct. The
+// attribute values, like {"S": "x"} or {"N": "1"}, are unwrapped into the
+// logical document before the types are inferred. The input can hold items,
+// one after another like in an S3 export ({"Item": {...}} per line), in the
+// output of a scan or query ({"Items": [...]}) or as bare attribute maps.
+// Every item is a sample of the root type.
+type DynamoDB struct {
+	File   string
+	reader io.Reader
+}
+
+// NewDynamoDBReader creates a DynamoDB decoder reading from r instead of a
+// file. The reader is consumed by the first Decode.
+func NewDynamoDBReader(r io.Reader) *DynamoDB {
+	return &DynamoDB{reader: r}
+}
+
+// NewDynamoDBBytes creates a DynamoDB decoder for the in-memory data
+func NewDynamoDBBytes(b []byte) *DynamoDB {
+	return NewDynamoDBReader(bytes.NewReader(b))
+}
+
+// Decode this DynamoDB instance into decodedData
+func (d *DynamoDB) Decode() (DecodedData, error) {
+
+	dd := new(DecodedData)
+	f, err := open(d.File, d.reader)
+	if err != nil {
+		log.Println("Error while reading file", err)
+		return *dd, err
+	}
+	defer f.Close()
+
+	dec := json.NewDecoder(f)
+	dec.UseNumber()
+	for {
+		var val map[string]interface{}
+		err := dec.Decode(&val)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Println("Error while decoding", err)
+			return *dd, err
+		}
+		var items []interface{}
+		if item, ok := val["Item"]; ok && len(val) == 1 && isDynamoDBItem(item) {
+			items = []interface{}{item}
+		} else if list, ok := val["Items"].([]interface{}); ok {
+			items = list
+		} else {
+			items = []interface{}{val}
+		}
+		for _, item := range items {
+			mp, ok := item.(map[string]interface{})
+			if !ok {
+				return *dd, fmt.Errorf("Expected an item but found %v", item)
+			}
+			doc, err := unwrapDynamoDBItem(mp)
+			if err != nil {
+				log.Println("Error while unwrapping", err)
+				return *dd, err
+			}
+			dd.documents = append(dd.documents, DecodedData{mapData: doc})
+		}
+	}
+	if len(dd.documents) == 0 {
+		return *dd, io.ErrUnexpectedEOF
+	}
+	if len(dd.documents) == 1 {
+		return dd.documents[0], nil
+	}
+	return *dd, nil
+}
+
+// dynamoDBTypes are the data type descriptors of the attribute values
+var dynamoDBTypes = map[string]bool{
+	"S": true, "N": true, "B": true, "BOOL": true, "NULL": true,
+	"M": true, "L": true, "SS": true, "NS": true, "BS": true,
+}
+
+// isDynamoDBItem checks if v is an item, i.e. an attribute map whose values
+// are all objects with a data type descriptor as their only key. It tells
+// the Item of an export line from a bare item with an attribute named Item.
+func isDynamoDBItem(v interface{}) bool {
+	item, ok := v.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	for _, av := range item {
+		mp, ok := av.(map[string]interface{})
+		if !ok || len(mp) != 1 {
+			return false
+		}
+		for tp := range mp {
+			if !dynamoDBTypes[tp] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// unwrapDynamoDBItem unwraps every attribute value of the item
+func unwrapDynamoDBItem(item map[string]interface{}) (map[string]interface{}, error) {
+	doc := make(map[string]interface{}, len(item))
+	for name, av := range item {
+		val, err := unwrapDynamoDB(av)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", name, err)
+		}
+		doc[name] = val
+	}
+	return doc, nil
+}
+
+// unwrapDynamoDB returns the value of an attribute value, an object with the
+// data type descriptor as its only key. Numbers become int, int64 or float64
+// and binary data becomes []byte.
+func unwrapDynamoDB(av interface{}) (interface{}, error) {
+	mp, ok := av.(map[string]interface{})
+	if !ok || len(mp) != 1 {
+		return nil, fmt.Errorf("Expected an attribute value but found %v", av)
+	}
+	for tp, val := range mp {
+		switch tp {
+		case "S":
+			s, ok := val.(string)
+			if !ok {
+				break
+			}
+			return s, nil
+		case "N":
+			s, ok := val.(string)
+			if !ok {
+				break
+			}
+			return scalarValue(s), nil
+		case "B":
+			s, ok := val.(string)
+			if !ok {
+				break
+			}
+			return base64.StdEncoding.DecodeString(s)
+		case "BOOL":
+			b, ok := val.(bool)
+			if !ok {
+				break
+			}
+			return b, nil
+		case "NULL":
+			return nil, nil
+		case "M":
+			m, ok := val.(map[string]interface{})
+			if !ok {
+				break
+			}
+			return unwrapDynamoDBItem(m)
+		case "L":
+			l, ok := val.([]interface{})
+			if !ok {
+				break
+			}
+			for i, e := range l {
+				ue, err := unwrapDynamoDB(e)
+				if err != nil {
+					return nil, fmt.Errorf("[%d]: %v", i, err)
+				}
+				l[i] = ue
+			}
+			return l, nil
+		case "SS", "NS", "BS":
+			l, ok := val.([]interface{})
+			if !ok {
+				break
+			}
+			for i, e := range l {
+				ue, err := unwrapDynamoDB(map[string]interface{}{tp[:1]: e})
+				if err != nil {
+					return nil, fmt.Errorf("[%d]: %v", i, err)
+				}
+				l[i] = ue
+			}
+			return l, nil
+		default:
+			return nil, fmt.Errorf("Unknown data type descriptor %s", tp)
+		}
+		return nil, fmt.Errorf("Invalid value %v of data type %s", val, tp)
+	}
+	return nil, nil
+}
+
+// Source of this DynamoDB instance, the file name or empty if read from a reader
+func (d *DynamoDB) Source() string {
+	return d.File
+}
+
+// Annotate a field name with its dynamodbav tag
+func (d *DynamoDB) Annotate(name string) string {
+	return "dynamodbav:" + name
+}
@@ -0,0 +1,95 @@
+package togo
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDynamoDB_Decode(t *testing.T) {
+	tests := []struct {
+		tc     string
+		data   string
+		docs   []map[string]interface{}
+		expErr bool
+	}{
+		{"Bare Item", `{"s": {"S": "x"}, "n": {"N": "1"}, "f": {"N": "1.5"}, "b": {"BOOL": true}, "z": {"NULL": true}}`,
+			[]map[string]interface{}{{"s": "x", "n": 1, "f": 1.5, "b": true, "z": nil}}, false},
+		{"Export Lines", "{\"Item\": {\"id\": {\"N\": \"1\"}}}\n{\"Item\": {\"id\": {\"N\": \"2\"}}}\n",
+			[]map[string]interface{}{{"id": 1}, {"id": 2}}, false},
+		{"Attribute Named Item", `{"Item": {"S": "x"}}`, []map[string]interface{}{{"Item": "x"}}, false},
+		{"Item Among Attributes", `{"Item": {"M": {"id": {"N": "1"}}}, "id": {"N": "2"}}`,
+			[]map[string]interface{}{{"Item": map[string]interface{}{"id": 1}, "id": 2}}, false},
+		{"Scan Output", `{"Items": [{"id": {"S": "a"}}, {"id": {"S": "b"}}], "Count": 2}`,
+			[]map[string]interface{}{{"id": "a"}, {"id": "b"}}, false},
+		{"Nested", `{"m": {"M": {"l": {"L": [{"N": "1"}, {"S": "x"}]}}}}`,
+			[]map[string]interface{}{{"m": map[string]interface{}{"l": []interface{}{1, "x"}}}}, false},
+		{"Sets", `{"ss": {"SS": ["a"]}, "ns": {"NS": ["1", "2"]}, "bs": {"BS": ["AQ=="]}}`,
+			[]map[string]interface{}{{"ss": []interface{}{"a"}, "ns": []interface{}{1, 2},
+				"bs": []interface{}{[]byte{1}}}}, false},
+		{"Binary", `{"b": {"B": "AQI="}}`, []map[string]interface{}{{"b": []byte{1, 2}}}, false},
+		{"Untyped Value", `{"s": "x"}`, nil, true},
+		{"Unknown Descriptor", `{"s": {"X": "x"}}`, nil, true},
+		{"Invalid Value", `{"s": {"N": 1}}`, nil, true},
+		{"Two Descriptors", `{"s": {"S": "x", "N": "1"}}`, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tc, func(t *testing.T) {
+			dd, err := NewDynamoDBBytes([]byte(tt.data)).Decode()
+			if tt.expErr {
+				if err == nil {
+					t.Errorf("TC: %s: Expected error but did not get any error", tt.tc)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("TC: %s: Did not expect error but got %+v", tt.tc, err)
+			}
+			docs := dd.documents
+			if len(docs) == 0 {
+				docs = []DecodedData{dd}
+			}
+			var got []map[string]interface{}
+			for _, doc := range docs {
+				got = append(got, doc.mapData)
+			}
+			if !reflect.DeepEqual(got, tt.docs) {
+				t.Errorf("TC: %s: Expected %+v but got %+v", tt.tc, tt.docs, got)
+			}
+		})
+	}
+}
+
+func TestParse_DynamoDB(t *testing.T) {
+	dec, err := NewFileDecoder(FormatDynamoDB, "samples/dynamodb/orders.json")
+	if err != nil {
+		t.Fatalf("NewFileDecoder failed: %+v", err)
+	}
+	if err = Parse(dec); err != nil {
+		t.Fatalf("Parse failed: %+v", err)
+	}
+	var buf bytes.Buffer
+	if err = WriteStructs(&buf); err != nil {
+		t.Fatalf("WriteStructs failed: %+v", err)
+	}
+	out := buf.String()
+	for _, exp := range []string{
+		"\tPk string `dynamodbav:\"pk\"`",
+		"\tTotal float64 `dynamodbav:\"total\"`",
+		"\tQty int `dynamodbav:\"qty\"`",
+		"\tTags []string `dynamodbav:\"tags\"`",
+		"\tCustomer Customer `dynamodbav:\"customer\"`",
+		"\tLines []Lines `dynamodbav:\"lines\"`",
+		"\tReceipt []byte `dynamodbav:\"receipt\"`",
+		"\tRatings []int `dynamodbav:\"ratings\"`",
+		"\tVip bool `dynamodbav:\"vip\"`",
+	} {
+		if !strings.Contains(out, exp) {
+			t.Errorf("Expected %q in the structs but got:\n%s", exp, out)
+		}
+	}
+	if strings.Contains(out, "`dynamodbav:\"S\"`") {
+		t.Errorf("Expected the attribute values to be unwrapped but got:\n%s", out)
+	}
+}
@@ -0,0 +1,217 @@
+package togo
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"strings"
+)
+
+// Env type structure to convert a .env file, or a captured env dump, to go
+// struct. Variables sharing a prefix are grouped into nested structs, e.g.
+// DB_HOST and DB_PORT become a DB struct with the fields Host and Port, and
+// every field is tagged with the full name of its variable. Nested groups are
+// named after their full prefix, e.g. DB_POOL_MAX is the field Max of DBPool.
+// Values go through scalar inference unless they are quoted, and only quoted
+// values can span several lines. The comment lines right before a variable
+// become the doc comment of its field.
+type Env struct {
+	File   string
+	reader io.Reader
+
+	names    keyTexts
+	comments keyTexts
+}
+
+// NewEnvReader creates an Env decoder reading from r instead of a file.
+// The reader is consumed by the first Decode.
+func NewEnvReader(r io.Reader) *Env {
+	return &Env{reader: r}
+}
+
+// NewEnvBytes creates an Env decoder for the in-memory data
+func NewEnvBytes(b []byte) *Env {
+	return NewEnvReader(bytes.NewReader(b))
+}
+
+// envVar is a variable read by the Env decoder
+type envVar struct {
+	name    string
+	value   interface{}
+	comment string
+}
+
+// Decode this Env instance into decodedData
+func (e *Env) Decode() (DecodedData, error) {
+
+	dd := new(DecodedData)
+	f, err := open(e.File, e.reader)
+	if err != nil {
+		log.Println("Error while reading file", err)
+		return *dd, err
+	}
+	defer f.Close()
+
+	vars := make(map[string]*envVar)
+	var last *envVar
+	var raw string
+	var pending []string
+	sc := bufio.NewScanner(f)
+	line := 1
+	for ; sc.Scan(); line++ {
+		if last != nil && envOpenQuote(raw) {
+			// The quoted value of the variable before spans several lines
+			raw += "\n" + sc.Text()
+			last.value = envValue(raw)
+			continue
+		}
+		l := strings.TrimSpace(sc.Text())
+		if l == "" {
+			pending = nil
+			continue
+		}
+		if l[0] == '#' {
+			pending = append(pending, strings.TrimSpace(l[1:]))
+			continue
+		}
+		l = strings.TrimPrefix(l, "export ")
+		l = strings.TrimPrefix(l, "declare -x ")
+		sep := strings.IndexByte(l, '=')
+		if sep <= 0 || strings.ContainsAny(l[:sep], " \t") {
+			return *dd, fmt.Errorf("line %d: expected NAME=value but found %s", line, l)
+		}
+		raw = strings.TrimSpace(l[sep+1:])
+		last = &envVar{name: strings.TrimSpace(l[:sep]), value: envValue(raw),
+			comment: strings.Join(pending, "\n")}
+		vars[last.name] = last
+		pending = nil
+	}
+	if err = sc.Err(); err != nil {
+		log.Println("Error while reading lines", err)
+		return *dd, err
+	}
+	if last != nil && envOpenQuote(raw) {
+		return *dd, fmt.Errorf("line %d: the quoted value of %s is not closed", line-1, last.name)
+	}
+
+	names := make([]string, 0, len(vars))
+	for n := range vars {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	e.names = make(keyTexts)
+	e.comments = make(keyTexts)
+	dd.mapData = e.group(vars, names, 0, rootName)
+	return *dd, nil
+}
+
+// group builds the map of the variables, whose names all share the first
+// words (separated by '_') up to skip. Variables whose next word is shared
+// by other variables are grouped into a nested map, keyed by that word. The
+// variables are recorded by parent, the struct name of the group.
+func (e *Env) group(vars map[string]*envVar, names []string, skip int, parent string) map[string]interface{} {
+	words := func(n string) []string {
+		return strings.Split(n, "_")[skip:]
+	}
+
+	groups := make(map[string][]string)
+	for _, n := range names {
+		if w := words(n); len(w) > 1 && w[0] != "" {
+			key := strings.ToLower(w[0])
+			groups[key] = append(groups[key], n)
+		}
+	}
+	mp := make(map[string]interface{})
+	for _, n := range names {
+		w := words(n)
+		key := strings.ToLower(w[0])
+		members := groups[key]
+		_, clash := vars[strings.Join(strings.Split(n, "_")[:skip+1], "_")]
+		if len(w) > 1 && len(members) > 1 && !clash {
+			if _, ok := mp[key]; !ok {
+				mp[key] = e.group(vars, members, skip+1, e.StructName(parent, key))
+			}
+			continue
+		}
+		key = strings.ToLower(strings.Join(w, "_"))
+		mp[key] = vars[n].value
+		if _, ok := e.names[parent][key]; !ok {
+			e.names.add(parent, key, n)
+		}
+		if vars[n].comment != "" {
+			e.comments.add(parent, key, vars[n].comment)
+		}
+	}
+	return mp
+}
+
+// envOpenQuote checks if the raw value of a variable is a quoted value whose
+// closing quote is still to come, i.e. on a following line
+func envOpenQuote(v string) bool {
+	if v == "" || (v[0] != '"' && v[0] != '\'') {
+		return false
+	}
+	for i := 1; i < len(v); i++ {
+		if v[0] == '"' && v[i] == '\\' {
+			i++
+			continue
+		}
+		if v[i] == v[0] {
+			return false
+		}
+	}
+	return true
+}
+
+// envValue returns the value of a variable. A quoted value is a string,
+// anything else is inferred after removing an inline comment.
+func envValue(v string) interface{} {
+	if len(v) > 1 && (v[0] == '"' || v[0] == '\'') {
+		if end := strings.LastIndexByte(v, v[0]); end > 0 {
+			s := v[1:end]
+			if v[0] == '"' {
+				s = strings.NewReplacer(`\n`, "\n", `\"`, `"`, `\\`, `\`).Replace(s)
+			}
+			return s
+		}
+	}
+	if idx := strings.Index(v, " #"); idx >= 0 {
+		v = strings.TrimSpace(v[:idx])
+	}
+	return scalarValue(v)
+}
+
+// StructName names the struct of a group after its full prefix, e.g. db_pool
+// for DB_POOL_MAX, so that groups sharing a sub-group name stay apart
+func (e *Env) StructName(parent, key string) string {
+	if parent == rootName {
+		return key
+	}
+	return parent + "_" + key
+}
+
+// AnnotateKey annotates a field with the env tag of the full variable name
+func (e *Env) AnnotateKey(parent, name string) string {
+	if n, ok := e.names[parent][name]; ok {
+		return "env:" + n
+	}
+	return ""
+}
+
+// Annotate a field name with its env tag
+func (e *Env) Annotate(name string) string {
+	return "env:" + strings.ToUpper(name)
+}
+
+// Comment returns the comment documenting the variable name of the group parent
+func (e *Env) Comment(parent, name string) string {
+	return e.comments[parent][name]
+}
+
+// Source of this Env instance, the file name or empty if read from a reader
+func (e *Env) Source() string {
+	return e.File
+}
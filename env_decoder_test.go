@@ -0,0 +1,106 @@
+package togo
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEnv_Decode(t *testing.T) {
+	tests := []struct {
+		tc     string
+		data   string
+		exp    map[string]interface{}
+		expErr bool
+	}{
+		{"Flat", "PORT=8080\nDEBUG=true\n", map[string]interface{}{"port": 8080, "debug": true}, false},
+		{"Grouped", "DB_HOST=x\nDB_PORT=5432\nAPP_NAME=y\n", map[string]interface{}{
+			"db": map[string]interface{}{"host": "x", "port": 5432}, "app_name": "y"}, false},
+		{"Nested Groups", "DB_POOL_MAX=1\nDB_POOL_MIN=0\nDB_HOST=x\n", map[string]interface{}{
+			"db": map[string]interface{}{"host": "x",
+				"pool": map[string]interface{}{"max": 1, "min": 0}}}, false},
+		{"Prefix Is A Variable", "DB=x\nDB_HOST=y\nDB_PORT=1\n", map[string]interface{}{
+			"db": "x", "db_host": "y", "db_port": 1}, false},
+		{"Quotes And Comments", "# c\nexport A=\"1 # 2\"\nB='x'\nC=3 # three\n", map[string]interface{}{
+			"a": "1 # 2", "b": "x", "c": 3}, false},
+		{"Env Dump", "declare -x HOME=\"/root\"\nPEM=\"line1\n\n# line2=x\n\\\"3\\\"\"\nA=1\n", map[string]interface{}{
+			"home": "/root", "pem": "line1\n\n# line2=x\n\"3\"", "a": 1}, false},
+		{"Single Quoted Lines", "PEM='a\nb'\n", map[string]interface{}{"pem": "a\nb"}, false},
+		{"Unquoted Continuation", "PEM=line1\nline2\n", nil, true},
+		{"Quote Not Closed", "PEM=\"line1\nline2\n", nil, true},
+		{"Value With Equals", "DSN=a=b\n", map[string]interface{}{"dsn": "a=b"}, false},
+		{"Not A Variable", "just text\n", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tc, func(t *testing.T) {
+			dd, err := NewEnvBytes([]byte(tt.data)).Decode()
+			if tt.expErr {
+				if err == nil {
+					t.Errorf("TC: %s: Expected error but did not get any error", tt.tc)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("TC: %s: Did not expect error but got %+v", tt.tc, err)
+			}
+			if !reflect.DeepEqual(dd.mapData, tt.exp) {
+				t.Errorf("TC: %s: Expected %+v but got %+v", tt.tc, tt.exp, dd.mapData)
+			}
+		})
+	}
+}
+
+func TestParse_Env(t *testing.T) {
+	dec, err := NewFileDecoder("", "samples/env/service.env")
+	if err != nil {
+		t.Fatalf("NewFileDecoder failed: %+v", err)
+	}
+	if err = Parse(dec); err != nil {
+		t.Fatalf("Parse failed: %+v", err)
+	}
+	var buf bytes.Buffer
+	if err = WriteStructs(&buf); err != nil {
+		t.Fatalf("WriteStructs failed: %+v", err)
+	}
+	out := buf.String()
+	for _, exp := range []string{
+		"\tDB DB\n",
+		"\tApp App\n",
+		"\tPort int `env:\"PORT\"`",
+		"\tLogLevel string `env:\"LOG_LEVEL\"`",
+		"\t// Name of the service in logs and metrics\n\tName string `env:\"APP_NAME\"`",
+		"\tDebug bool `env:\"APP_DEBUG\"`",
+		"\t// Connection to the primary database\n\tHost string `env:\"DB_HOST\"`",
+		"\tPassword string `env:\"DB_PASSWORD\"`",
+		"\tMax int `env:\"DB_POOL_MAX\"`",
+		"\tURL string `env:\"REDIS_URL\"`",
+		"\tTTL float64 `env:\"REDIS_TTL\"`",
+	} {
+		if !strings.Contains(out, exp) {
+			t.Errorf("Expected %q in the structs but got:\n%s", exp, out)
+		}
+	}
+}
+
+func TestParse_EnvSharedGroups(t *testing.T) {
+	env := "DB_HOST=x\nDB_POOL_MAX=10\nDB_POOL_MIN=1\nCACHE_URL=y\nCACHE_POOL_MAX=20\nCACHE_POOL_MIN=2\n"
+	if err := Parse(NewEnvBytes([]byte(env))); err != nil {
+		t.Fatalf("Parse failed: %+v", err)
+	}
+	var buf bytes.Buffer
+	if err := WriteStructs(&buf); err != nil {
+		t.Fatalf("WriteStructs failed: %+v", err)
+	}
+	out := buf.String()
+	for _, exp := range []string{
+		"\tPool DBPool\n",
+		"\tPool CachePool\n",
+		"type DBPool struct {\n\tMax int `env:\"DB_POOL_MAX\"`\n\tMin int `env:\"DB_POOL_MIN\"`",
+		"type CachePool struct {\n\tMax int `env:\"CACHE_POOL_MAX\"`\n\tMin int `env:\"CACHE_POOL_MIN\"`",
+	} {
+		if !strings.Contains(out, exp) {
+			t.Errorf("Expected %q in the structs but got:\n%s", exp, out)
+		}
+	}
+}
@@ -68,3 +68,12 @@ type GoStructError struct {
 func (gse GoStructError) Error() string {
 	return fmt.Sprintf("GoStruct %s errored due to %s", gse.gs.Name, gse.message)
 }
+
+// UnsupportedFormat is an error type whenever an input format has no Decoder
+type UnsupportedFormat struct {
+	format string
+}
+
+func (uf UnsupportedFormat) Error() string {
+	return fmt.Sprintf("Format %q is not supported", uf.format)
+}
@@ -0,0 +1,249 @@
+package togo
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Form type structure to convert application/x-www-form-urlencoded bodies, or
+// URL query strings, to go struct. Keys that are repeated become slices and
+// bracketed keys nest: filter[name]=x becomes a filter struct with a name
+// field, items[0][id]=1 and tags[]=a become slices. Values go through scalar
+// inference, with the elements of a slice sharing one type. Every non-empty
+// line of the input is a sample of the same type.
+type Form struct {
+	File   string
+	reader io.Reader
+	// Query is set if the data are URL query strings rather than form bodies.
+	// Fields are then tagged query instead of form, and a line can be a
+	// complete URL whose query string is decoded.
+	Query bool
+}
+
+// NewFormReader creates a Form decoder reading from r instead of a file.
+// The reader is consumed by the first Decode.
+func NewFormReader(r io.Reader) *Form {
+	return &Form{reader: r}
+}
+
+// NewFormBytes creates a Form decoder for the in-memory data
+func NewFormBytes(b []byte) *Form {
+	return NewFormReader(bytes.NewReader(b))
+}
+
+// NewQueryBytes creates a Form decoder for the in-memory query strings
+func NewQueryBytes(b []byte) *Form {
+	f := NewFormBytes(b)
+	f.Query = true
+	return f
+}
+
+// Decode this Form instance into decodedData
+func (fm *Form) Decode() (DecodedData, error) {
+
+	dd := new(DecodedData)
+	f, err := open(fm.File, fm.reader)
+	if err != nil {
+		log.Println("Error while reading file", err)
+		return *dd, err
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; sc.Scan(); line++ {
+		l := strings.TrimSpace(sc.Text())
+		if fm.Query {
+			if idx := strings.IndexByte(l, '#'); idx >= 0 {
+				l = l[:idx]
+			}
+			if idx := strings.IndexByte(l, '?'); idx >= 0 {
+				l = l[idx+1:]
+			}
+		}
+		if l == "" {
+			continue
+		}
+		doc, err := parseForm(l)
+		if err != nil {
+			return *dd, fmt.Errorf("line %d: %v", line, err)
+		}
+		dd.documents = append(dd.documents, DecodedData{mapData: doc})
+	}
+	if err = sc.Err(); err != nil {
+		log.Println("Error while reading lines", err)
+		return *dd, err
+	}
+	switch len(dd.documents) {
+	case 0:
+		return *dd, fmt.Errorf("No form data to decode")
+	case 1:
+		return dd.documents[0], nil
+	}
+	return *dd, nil
+}
+
+// parseForm decodes the pairs of a form body or query string into a map,
+// nesting the bracketed keys
+func parseForm(s string) (map[string]interface{}, error) {
+	doc := make(map[string]interface{})
+	for _, pair := range strings.FieldsFunc(s, func(r rune) bool { return r == '&' || r == ';' }) {
+		k, v := pair, ""
+		if idx := strings.IndexByte(pair, '='); idx >= 0 {
+			k, v = pair[:idx], pair[idx+1:]
+		}
+		key, err := url.QueryUnescape(k)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %s: %v", k, err)
+		}
+		val, err := url.QueryUnescape(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value of %s: %v", key, err)
+		}
+		path, err := formPath(key)
+		if err != nil {
+			return nil, err
+		}
+		if err = setForm(doc, path, val); err != nil {
+			return nil, fmt.Errorf("%s: %v", key, err)
+		}
+	}
+	if len(doc) == 0 {
+		return nil, fmt.Errorf("no key in %s", s)
+	}
+	return formValues(doc).(map[string]interface{}), nil
+}
+
+// formPath splits a bracketed key, e.g. items[0][id], into its parts. An
+// empty part, as in tags[], appends to a slice.
+func formPath(key string) ([]string, error) {
+	idx := strings.IndexByte(key, '[')
+	if idx < 0 || !strings.HasSuffix(key, "]") {
+		if key == "" {
+			return nil, fmt.Errorf("empty key")
+		}
+		return []string{key}, nil
+	}
+	if idx == 0 {
+		return nil, fmt.Errorf("key %s has no name before the brackets", key)
+	}
+	path := []string{key[:idx]}
+	for rest := key[idx:]; rest != ""; {
+		end := strings.IndexByte(rest, ']')
+		if rest[0] != '[' || end < 0 {
+			return nil, fmt.Errorf("invalid brackets in key %s", key)
+		}
+		path = append(path, rest[1:end])
+		rest = rest[end+1:]
+	}
+	return path, nil
+}
+
+// setForm sets the value at the path of doc. Maps are created along the way,
+// with the maps of indices turned into slices by formValues. Setting a value
+// that is already set turns it into a slice of both.
+func setForm(doc map[string]interface{}, path []string, val string) error {
+	for i, key := range path {
+		if key == "" {
+			key = strconv.Itoa(len(doc))
+		}
+		if i == len(path)-1 {
+			switch ex := doc[key].(type) {
+			case nil:
+				doc[key] = val
+			case string:
+				doc[key] = []interface{}{ex, val}
+			case []interface{}:
+				doc[key] = append(ex, val)
+			default:
+				return fmt.Errorf("%s is a value and a prefix of other keys", key)
+			}
+			return nil
+		}
+		switch ex := doc[key].(type) {
+		case nil:
+			mp := make(map[string]interface{})
+			doc[key] = mp
+			doc = mp
+		case map[string]interface{}:
+			doc = ex
+		default:
+			return fmt.Errorf("%s is a value and a prefix of other keys", key)
+		}
+	}
+	return nil
+}
+
+// formValues turns the maps whose keys are all indices into slices, ordered
+// by index, and converts the text of the values to their inferred types
+func formValues(v interface{}) interface{} {
+	switch val := v.(type) {
+	case string:
+		return scalarValue(val)
+	case []interface{}:
+		return formSlice(val)
+	case map[string]interface{}:
+		indices := make([]int, 0, len(val))
+		for k := range val {
+			idx, err := strconv.Atoi(k)
+			if err != nil || idx < 0 {
+				indices = nil
+				break
+			}
+			indices = append(indices, idx)
+		}
+		if len(indices) == 0 {
+			for k, e := range val {
+				val[k] = formValues(e)
+			}
+			return val
+		}
+		sort.Ints(indices)
+		sl := make([]interface{}, len(indices))
+		for i, idx := range indices {
+			sl[i] = val[strconv.Itoa(idx)]
+		}
+		return formSlice(sl)
+	default:
+		return v
+	}
+}
+
+// formSlice converts the elements of a slice. Text elements are converted
+// to one type that can hold all of them.
+func formSlice(sl []interface{}) interface{} {
+	var dt FieldDT
+	for _, e := range sl {
+		if s, ok := e.(string); ok {
+			dt = widen(dt, inferScalar(s))
+		}
+	}
+	for i, e := range sl {
+		if s, ok := e.(string); ok {
+			sl[i] = convertScalar(s, dt)
+		} else {
+			sl[i] = formValues(e)
+		}
+	}
+	return sl
+}
+
+// Source of this Form instance, the file name or empty if read from a reader
+func (fm *Form) Source() string {
+	return fm.File
+}
+
+// Annotate a field name with its form tag, or query tag for query strings
+func (fm *Form) Annotate(name string) string {
+	if fm.Query {
+		return "query:" + name
+	}
+	return "form:" + name
+}
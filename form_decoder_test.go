@@ -0,0 +1,89 @@
+package togo
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestForm_Decode(t *testing.T) {
+	tests := []struct {
+		tc     string
+		data   string
+		query  bool
+		exp    map[string]interface{}
+		expErr bool
+	}{
+		{"Flat", "name=togo&id=1&ok=true", false, map[string]interface{}{
+			"name": "togo", "id": 1, "ok": true}, false},
+		{"Escapes", "q=a+b%26c&empty=", false, map[string]interface{}{"q": "a b&c", "empty": ""}, false},
+		{"Repeated Keys", "tag=1&tag=2&tag=x", false, map[string]interface{}{
+			"tag": []interface{}{"1", "2", "x"}}, false},
+		{"Nested Map", "filter[name]=x&filter[age][gt]=3", false, map[string]interface{}{
+			"filter": map[string]interface{}{"name": "x",
+				"age": map[string]interface{}{"gt": 3}}}, false},
+		{"Indexed Slice", "items[1][id]=2&items[0][id]=1&items[0][name]=a", false, map[string]interface{}{
+			"items": []interface{}{map[string]interface{}{"id": 1, "name": "a"},
+				map[string]interface{}{"id": 2}}}, false},
+		{"Appended Slice", "ids[]=1&ids[]=2.5", false, map[string]interface{}{
+			"ids": []interface{}{1.0, 2.5}}, false},
+		{"Query URL", "https://example.com/search?q=go&page=2#top", true, map[string]interface{}{
+			"q": "go", "page": 2}, false},
+		{"Value And Prefix", "a=1&a[b]=2", false, nil, true},
+		{"Bad Brackets", "a[b]x[c]=1", false, nil, true},
+		{"Bad Escape", "a=%zz", false, nil, true},
+		{"Empty", "\n", false, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tc, func(t *testing.T) {
+			dec := NewFormBytes([]byte(tt.data))
+			if tt.query {
+				dec = NewQueryBytes([]byte(tt.data))
+			}
+			dd, err := dec.Decode()
+			if tt.expErr {
+				if err == nil {
+					t.Errorf("TC: %s: Expected error but did not get any error", tt.tc)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("TC: %s: Did not expect error but got %+v", tt.tc, err)
+			}
+			if !reflect.DeepEqual(dd.mapData, tt.exp) {
+				t.Errorf("TC: %s: Expected %+v but got %+v", tt.tc, tt.exp, dd.mapData)
+			}
+		})
+	}
+}
+
+func TestParse_Form(t *testing.T) {
+	dec, err := NewFileDecoder(FormatForm, "samples/form/signup.form")
+	if err != nil {
+		t.Fatalf("NewFileDecoder failed: %+v", err)
+	}
+	if err = Parse(dec); err != nil {
+		t.Fatalf("Parse failed: %+v", err)
+	}
+	var buf bytes.Buffer
+	if err = WriteStructs(&buf); err != nil {
+		t.Fatalf("WriteStructs failed: %+v", err)
+	}
+	out := buf.String()
+	for _, exp := range []string{
+		"\tName string `form:\"name\"`",
+		"\tAge int `form:\"age\"`",
+		"\tNewsletter bool `form:\"newsletter\"`",
+		"\tInterests []string `form:\"interests\"`",
+		"\tAddress Address `form:\"address\"`",
+		"\tZip int `form:\"zip\"`",
+		"\tItems []Items `form:\"items\"`",
+		"\tSku string `form:\"sku\"`",
+		"\tPrice float64 `form:\"price\"`",
+	} {
+		if !strings.Contains(out, exp) {
+			t.Errorf("Expected %q in the structs but got:\n%s", exp, out)
+		}
+	}
+}
@@ -0,0 +1,280 @@
+package togo
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Formats understood by NewReaderDecoder
+const (
+	FormatJSON       = "json"
+	FormatNDJSON     = "ndjson"
+	FormatJSONC      = "jsonc"
+	FormatJSON5      = "json5"
+	FormatMsgPack    = "msgpack"
+	FormatCBOR       = "cbor"
+	FormatBSON       = "bson"
+	FormatExtJSON    = "extjson"
+	FormatDynamoDB   = "dynamodb"
+	FormatINI        = "ini"
+	FormatProperties = "properties"
+	FormatEnv        = "env"
+	FormatLogfmt     = "logfmt"
+	FormatForm       = "form"
+	FormatQuery      = "query"
+	FormatHAR        = "har"
+	FormatPostman    = "postman"
+	FormatJSONSchema = "jsonschema"
+	FormatOpenAPI    = "openapi"
+	FormatAvro       = "avro"
+	FormatProto      = "proto"
+	FormatYAML       = "yaml"
+	FormatTOML       = "toml"
+	FormatXML        = "xml"
+	FormatCSV        = "csv"
+	FormatTSV        = "tsv"
+)
+
+// extFormats maps file extensions onto the format of the file
+var extFormats = map[string]string{
+	".json":       FormatJSON,
+	".ndjson":     FormatNDJSON,
+	".jsonl":      FormatNDJSON,
+	".jsonc":      FormatJSONC,
+	".json5":      FormatJSON5,
+	".msgpack":    FormatMsgPack,
+	".mpk":        FormatMsgPack,
+	".cbor":       FormatCBOR,
+	".bson":       FormatBSON,
+	".ini":        FormatINI,
+	".properties": FormatProperties,
+	".env":        FormatEnv,
+	".logfmt":     FormatLogfmt,
+	".har":        FormatHAR,
+	".avsc":       FormatAvro,
+	".proto":      FormatProto,
+	".yaml":       FormatYAML,
+	".yml":        FormatYAML,
+	".toml":       FormatTOML,
+	".xml":        FormatXML,
+	".csv":        FormatCSV,
+	".tsv":        FormatTSV,
+}
+
+// suffixFormats maps the suffixes of the names of files that are told apart
+// from other files with the same extension onto their format
+var suffixFormats = map[string]string{
+	".postman_collection.json": FormatPostman,
+	".schema.json":             FormatJSONSchema,
+	".schema.yaml":             FormatJSONSchema,
+	"openapi.json":             FormatOpenAPI,
+	"openapi.yaml":             FormatOpenAPI,
+	"openapi.yml":              FormatOpenAPI,
+}
+
+// FormatOf guesses the format of a file from its extension, looking through
+// the extension of a compression (e.g. "data.json.gz"). Postman exports and
+// schemas are told apart from other JSON by the suffixes of suffixFormats.
+// It returns an empty string if the extension is not known.
+func FormatOf(file string) string {
+	file = strings.ToLower(file)
+	for _, ext := range compressionExts {
+		file = strings.TrimSuffix(file, ext)
+	}
+	for sfx, format := range suffixFormats {
+		if strings.HasSuffix(file, sfx) {
+			return format
+		}
+	}
+	return extFormats[filepath.Ext(file)]
+}
+
+// NewReaderDecoder returns a Decoder for data of the given format read from r
+func NewReaderDecoder(format string, r io.Reader) (Decoder, error) {
+	return newDecoder(format, "", r)
+}
+
+// NewFileDecoder returns a Decoder for the file. The format is guessed from
+// the extension of the file if it is not given.
+func NewFileDecoder(format, file string) (Decoder, error) {
+	if format == "" {
+		format = FormatOf(file)
+	}
+	return newDecoder(format, file, nil)
+}
+
+// newDecoder returns a Decoder for data of the given format. The data is
+// read from r, or from file if r is nil. Either way the file names the
+// source of the data.
+func newDecoder(format, file string, r io.Reader) (Decoder, error) {
+	switch strings.ToLower(format) {
+	case FormatJSON:
+		return &JSON{File: file, reader: r}, nil
+	case FormatNDJSON:
+		return &NDJSON{File: file, reader: r}, nil
+	case FormatJSONC, FormatJSON5:
+		return &JSON5{File: file, reader: r}, nil
+	case FormatMsgPack:
+		return &MsgPack{File: file, reader: r}, nil
+	case FormatCBOR:
+		return &CBOR{File: file, reader: r}, nil
+	case FormatBSON:
+		return &BSON{File: file, reader: r}, nil
+	case FormatExtJSON:
+		return &ExtJSON{File: file, reader: r}, nil
+	case FormatDynamoDB:
+		return &DynamoDB{File: file, reader: r}, nil
+	case FormatINI:
+		return &INI{File: file, reader: r}, nil
+	case FormatProperties:
+		return &Properties{File: file, reader: r}, nil
+	case FormatEnv:
+		return &Env{File: file, reader: r}, nil
+	case FormatLogfmt:
+		return &Logfmt{File: file, reader: r}, nil
+	case FormatForm:
+		return &Form{File: file, reader: r}, nil
+	case FormatQuery:
+		return &Form{File: file, reader: r, Query: true}, nil
+	case FormatHAR:
+		return &HAR{File: file, reader: r}, nil
+	case FormatPostman:
+		return &Postman{File: file, reader: r}, nil
+	case FormatJSONSchema:
+		return &JSONSchema{File: file, reader: r}, nil
+	case FormatOpenAPI:
+		return &OpenAPI{File: file, reader: r}, nil
+	case FormatAvro:
+		return &Avro{File: file, reader: r}, nil
+	case FormatProto:
+		return &Proto{File: file, reader: r}, nil
+	case FormatYAML:
+		return &YAML{File: file, reader: r}, nil
+	case FormatTOML:
+		return &TOML{File: file, reader: r}, nil
+	case FormatXML:
+		return &XML{File: file, reader: r}, nil
+	case FormatCSV:
+		return &CSV{File: file, reader: r}, nil
+	case FormatTSV:
+		return &CSV{File: file, reader: r, Delimiter: '\t'}, nil
+	default:
+		return nil, UnsupportedFormat{format: format}
+	}
+}
+
+// ExpandInputs expands the inputs into the list of files to decode. An input
+// can be a file, a glob pattern or a directory, which is walked recursively
+// for the files of the given format, or of any known format if format is
+// empty, and for archives, whose entries are filtered by format when read.
+// The files are returned sorted and without duplicates.
+func ExpandInputs(format string, inputs ...string) ([]string, error) {
+	seen := make(map[string]bool)
+	var files []string
+	add := func(f string) {
+		if !seen[f] {
+			seen[f] = true
+			files = append(files, f)
+		}
+	}
+
+	for _, in := range inputs {
+		matches := []string{in}
+		if strings.ContainsAny(in, "*?[") {
+			var err error
+			matches, err = filepath.Glob(in)
+			if err != nil {
+				return nil, err
+			}
+			if len(matches) == 0 {
+				return nil, fmt.Errorf("No file matches %s", in)
+			}
+		}
+		for _, m := range matches {
+			st, err := os.Stat(m)
+			if err != nil {
+				return nil, err
+			}
+			if !st.IsDir() {
+				add(m)
+				continue
+			}
+			err = filepath.Walk(m, func(path string, info os.FileInfo, err error) error {
+				if err != nil {
+					return err
+				}
+				ff := FormatOf(path)
+				if !info.Mode().IsRegular() {
+					return nil
+				}
+				// The entries of tar and zip archives are filtered by format
+				// once the archives are read
+				if (ff == "" && isArchiveName(path)) || (ff != "" && (format == "" || ff == format)) {
+					add(path)
+				}
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// NewFileDecoders returns a Decoder for every file the inputs expand to, as
+// done by ExpandInputs, and for every entry of the archives among them.
+// Passing all of them to Parse infers a single type from all the samples.
+func NewFileDecoders(format string, inputs ...string) ([]Decoder, error) {
+	files, err := ExpandInputs(format, inputs...)
+	if err != nil {
+		return nil, err
+	}
+	decs := make([]Decoder, 0, len(files))
+	for _, f := range files {
+		kind, err := archiveKind(f)
+		if err != nil {
+			return nil, err
+		}
+		if kind != "" {
+			adecs, err := archiveDecoders(format, f, kind)
+			if err != nil {
+				return nil, err
+			}
+			decs = append(decs, adecs...)
+			continue
+		}
+		dec, err := NewFileDecoder(format, f)
+		if err != nil {
+			return nil, err
+		}
+		decs = append(decs, dec)
+	}
+	return decs, nil
+}
+
+// open returns what a decoder reads from: the reader it was created with,
+// or else its file. Compressed data is transparently decompressed. The
+// returned ReadCloser must be closed by the caller.
+func open(file string, r io.Reader) (io.ReadCloser, error) {
+	var c io.Closer = ioutil.NopCloser(nil)
+	if r == nil {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, err
+		}
+		r, c = f, f
+	}
+	dr, err := decompress(r)
+	if err != nil {
+		c.Close()
+		return nil, err
+	}
+	return readCloser{dr, c}, nil
+}
@@ -0,0 +1,200 @@
+package togo
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestNewReaderDecoder(t *testing.T) {
+	tests := []struct {
+		tc     string
+		format string
+		data   string
+		key    string
+		expErr bool
+	}{
+		{"JSON", FormatJSON, `{"name": "togo"}`, "name", false},
+		{"NDJSON", FormatNDJSON, "{\"a\": 1}\n{\"name\": \"togo\"}\n", "name", false},
+		{"YAML", FormatYAML, "name: togo\n", "name", false},
+		{"TOML", FormatTOML, "name = \"togo\"\n", "name", false},
+		{"XML", FormatXML, "<doc><name>togo</name></doc>", "name", false},
+		{"CSV", FormatCSV, "name,id\ntogo,1\n", "name", false},
+		{"TSV", FormatTSV, "name\tid\ntogo\t1\n", "name", false},
+		{"INI", FormatINI, "name = togo\n", "name", false},
+		{"Properties", FormatProperties, "name=togo\n", "name", false},
+		{"Unknown Format", "hcl", "name = \"togo\"", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tc, func(t *testing.T) {
+			dec, err := NewReaderDecoder(tt.format, strings.NewReader(tt.data))
+			if tt.expErr {
+				if err == nil {
+					t.Errorf("TC: %s: Expected error but did not get any error", tt.tc)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("TC: %s: Did not expect error but got %#v", tt.tc, err)
+			}
+			dd, err := dec.Decode()
+			if err != nil {
+				t.Fatalf("TC: %s: Decode failed: %+v", tt.tc, err)
+			}
+			if len(dd.documents) > 0 {
+				dd = dd.documents[len(dd.documents)-1]
+			}
+			if dd.mapData[tt.key] != "togo" {
+				t.Errorf("TC: %s: Expected %s to be decoded, got %+v", tt.tc, tt.key, dd)
+			}
+		})
+	}
+}
+
+func TestParse_Reader(t *testing.T) {
+	dec := NewJSONBytes([]byte(`[{"id": 1, "tags": ["a"]}, {"id": 2, "name": "b"}]`))
+	if err := Parse(dec); err != nil {
+		t.Fatalf("Parse failed: %+v", err)
+	}
+	var buf bytes.Buffer
+	if err := WriteStructs(&buf); err != nil {
+		t.Fatalf("WriteStructs failed: %+v", err)
+	}
+	for _, exp := range []string{"type Document struct {", "Tags []string `json:\"tags\"`",
+		"Name string `json:\"name\"`"} {
+		if !strings.Contains(buf.String(), exp) {
+			t.Errorf("Expected %q in the generated code:\n%s", exp, buf.String())
+		}
+	}
+}
+
+func TestFormatOf(t *testing.T) {
+	tests := []struct {
+		file   string
+		format string
+	}{
+		{"a/b/c.json", FormatJSON},
+		{"events.JSONL", FormatNDJSON},
+		{"deploy.yml", FormatYAML},
+		{"data.tsv", FormatTSV},
+		{"Shop.postman_collection.json", FormatPostman},
+		{"order.schema.json.gz", FormatJSONSchema},
+		{"api/openapi.yaml", FormatOpenAPI},
+		{"schemas/user.avsc", FormatAvro},
+		{"api/order.proto", FormatProto},
+		{"README", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			if f := FormatOf(tt.file); f != tt.format {
+				t.Errorf("TC: %s: Expected %q but got %q", tt.file, tt.format, f)
+			}
+		})
+	}
+}
+
+func TestExpandInputs(t *testing.T) {
+	tests := []struct {
+		tc     string
+		format string
+		inputs []string
+		files  int
+		expErr bool
+	}{
+		{"Explicit Files", "", []string{"samples/json/twitter.json", "samples/json/users.json"}, 2, false},
+		{"Duplicate Files", "", []string{"samples/json/users.json", "samples/json/users.json"}, 1, false},
+		{"Glob", "", []string{"samples/json/responses/user-*.json"}, 3, false},
+		{"Directory", "", []string{"samples/json/responses"}, 3, false},
+		{"Directory With Format", FormatTSV, []string{"samples"}, 3, false},
+		{"Archives With Format", FormatJSON, []string{"samples/archive"}, 4, false},
+		{"Glob Without Match", "", []string{"samples/json/none-*.json"}, 0, true},
+		{"Missing File", "", []string{"samples/json/missing.json"}, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tc, func(t *testing.T) {
+			files, err := ExpandInputs(tt.format, tt.inputs...)
+			if tt.expErr && err == nil {
+				t.Errorf("TC: %s: Expected error but did not get any error", tt.tc)
+			} else if !tt.expErr && err != nil {
+				t.Errorf("TC: %s: Did not expect error but got %#v", tt.tc, err)
+			}
+			if len(files) != tt.files {
+				t.Errorf("TC: %s: Expected %d files but got %v", tt.tc, tt.files, files)
+			}
+		})
+	}
+}
+
+func TestParse_MultipleFiles(t *testing.T) {
+	decs, err := NewFileDecoders("", "samples/json/responses")
+	if err != nil {
+		t.Fatalf("NewFileDecoders failed: %+v", err)
+	}
+	if err = Parse(decs...); err != nil {
+		t.Fatalf("Parse failed: %+v", err)
+	}
+	tests := []struct {
+		tc      string
+		gsName  string
+		field   string
+		sources []string
+	}{
+		{"Field In Every File", "Document", "id", []string{
+			"samples/json/responses/user-1.json",
+			"samples/json/responses/user-2.json",
+			"samples/json/responses/user-3.json",
+		}},
+		{"Field In One File", "Document", "email", []string{"samples/json/responses/user-1.json"}},
+		{"Null Then Value", "Document", "phone", []string{
+			"samples/json/responses/user-2.json",
+			"samples/json/responses/user-3.json",
+		}},
+		{"Nested Field", "address", "zip", []string{"samples/json/responses/user-2.json"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tc, func(t *testing.T) {
+			fld, ok := NameStructCache[tt.gsName].Fields[tt.field]
+			if !ok {
+				t.Fatalf("TC: %s: Field %s missing in %s", tt.tc, tt.field, tt.gsName)
+			}
+			if strings.Join(fld.Sources(), ",") != strings.Join(tt.sources, ",") {
+				t.Errorf("TC: %s: Expected sources %v but got %v", tt.tc, tt.sources, fld.Sources())
+			}
+		})
+	}
+
+	var buf bytes.Buffer
+	if err = WriteStructs(&buf); err != nil {
+		t.Fatalf("WriteStructs failed: %+v", err)
+	}
+	out := buf.String()
+	for _, exp := range []string{
+		"\t// from: samples/json/responses/user-1.json\n\tEmail string `json:\"email\"`",
+		"\t// from: samples/json/responses/user-2.json, samples/json/responses/user-3.json\n" +
+			"\tPhone string `json:\"phone\"`",
+		"\tID float64 `json:\"id\"`\n\tName string `json:\"name\"`\n",
+	} {
+		if !strings.Contains(out, exp) {
+			t.Errorf("Expected %q in the structs but got:\n%s", exp, out)
+		}
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		tc  string
+		dec Decoder
+	}{
+		{"Conflicting Documents", NewNDJSONBytes([]byte("{\"a\": {\"b\": 1}}\n{\"a\": [1]}\n"))},
+		{"Extended JSON As JSON", &JSON{File: "samples/extjson/users.json"}},
+		{"Invalid Stream", NewJSONBytes([]byte(`{"a": }`))},
+		{"Invalid Schema", NewJSONSchemaBytes([]byte(`{"type": "object", "properties": {"a": {"$ref": "#/missing"}}}`))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tc, func(t *testing.T) {
+			if err := Parse(tt.dec); err == nil {
+				t.Errorf("TC: %s: Expected error but did not get any error", tt.tc)
+			}
+		})
+	}
+}
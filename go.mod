@@ -2,4 +2,10 @@ module github.com/daichi-m/togo
 
 go 1.13
 
-require go.uber.org/zap v1.15.0
+require (
+	github.com/fxamacker/cbor/v2 v2.2.0
+	github.com/pelletier/go-toml v1.8.0
+	github.com/vmihailenco/msgpack/v4 v4.3.12
+	go.uber.org/zap v1.15.0
+	gopkg.in/yaml.v3 v3.0.0-20200615113413-eeeca48fe776
+)
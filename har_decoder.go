@@ -0,0 +1,318 @@
+package togo
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"strings"
+	"unicode"
+)
+
+// HAR type structure to convert the HTTP Archive of a browser or proxy
+// capture to go structs. Entries are grouped by endpoint, i.e. method and
+// path with the ids in the path normalised, and every endpoint gets a type
+// for its request body, its response body and its query parameters, named
+// after the route: GET /api/v1/users/42 gives GetUsersByIDResponse. The
+// objects inside a body are named after the endpoint type and their key, e.g.
+// GetUsersByIDResponseAddress. Bodies are decoded by the decoder of their
+// content type and only successful (2xx) responses are used. The root type
+// holds one field per endpoint type.
+type HAR struct {
+	File   string
+	reader io.Reader
+
+	tags keyTexts
+}
+
+// NewHARReader creates a HAR decoder reading from r instead of a file.
+// The reader is consumed by the first Decode.
+func NewHARReader(r io.Reader) *HAR {
+	return &HAR{reader: r}
+}
+
+// NewHARBytes creates a HAR decoder for the in-memory data
+func NewHARBytes(b []byte) *HAR {
+	return NewHARReader(bytes.NewReader(b))
+}
+
+// harFile is the part of a HTTP Archive used by the HAR decoder
+type harFile struct {
+	Log struct {
+		Entries []struct {
+			Request struct {
+				Method      string    `json:"method"`
+				URL         string    `json:"url"`
+				QueryString []harPair `json:"queryString"`
+				PostData    *struct {
+					MimeType string    `json:"mimeType"`
+					Text     string    `json:"text"`
+					Params   []harPair `json:"params"`
+				} `json:"postData"`
+			} `json:"request"`
+			Response struct {
+				Status  int `json:"status"`
+				Content struct {
+					MimeType string `json:"mimeType"`
+					Text     string `json:"text"`
+					Encoding string `json:"encoding"`
+				} `json:"content"`
+			} `json:"response"`
+		} `json:"entries"`
+	} `json:"log"`
+}
+
+// harPair is a query parameter or form parameter of a request
+type harPair struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Decode this HAR instance into decodedData
+func (h *HAR) Decode() (DecodedData, error) {
+
+	dd := new(DecodedData)
+	f, err := open(h.File, h.reader)
+	if err != nil {
+		log.Println("Error while reading file", err)
+		return *dd, err
+	}
+	defer f.Close()
+
+	var har harFile
+	if err = json.NewDecoder(f).Decode(&har); err != nil {
+		log.Println("Error while decoding", err)
+		return *dd, err
+	}
+
+	h.tags = make(keyTexts)
+	for idx, e := range har.Log.Entries {
+		route, err := routeName(e.Request.Method, e.Request.URL)
+		if err != nil {
+			return *dd, fmt.Errorf("entry %d: %v", idx, err)
+		}
+		doc := make(map[string]interface{})
+		if len(e.Request.QueryString) > 0 {
+			q, err := paramsMap(e.Request.QueryString)
+			if err != nil {
+				return *dd, fmt.Errorf("entry %d: %v", idx, err)
+			}
+			addBody(doc, h.tags, route+"_query", q, &Form{Query: true})
+		}
+		if pd := e.Request.PostData; pd != nil {
+			if strings.TrimSpace(pd.Text) == "" && len(pd.Params) > 0 {
+				p, err := paramsMap(pd.Params)
+				if err != nil {
+					return *dd, fmt.Errorf("entry %d: %v", idx, err)
+				}
+				addBody(doc, h.tags, route+"_request", p, &Form{})
+			} else if val, ann := decodeBody(pd.MimeType, pd.Text, ""); val != nil {
+				addBody(doc, h.tags, route+"_request", val, ann)
+			}
+		}
+		if st := e.Response.Status; st >= 200 && st < 300 {
+			c := e.Response.Content
+			if val, ann := decodeBody(c.MimeType, c.Text, c.Encoding); val != nil {
+				addBody(doc, h.tags, route+"_response", val, ann)
+			}
+		}
+		if len(doc) > 0 {
+			dd.documents = append(dd.documents, DecodedData{mapData: doc})
+		}
+	}
+	switch len(dd.documents) {
+	case 0:
+		return *dd, errors.New("No entry with a body or query parameters to decode")
+	case 1:
+		return dd.documents[0], nil
+	}
+	return *dd, nil
+}
+
+// addBody adds the value of an endpoint type to the document of a sample,
+// recording in tags how the decoder of the value annotates its keys. The tags
+// are kept by the names bodyStructName gives the structs of the value.
+func addBody(doc map[string]interface{}, tags keyTexts, name string, val interface{}, ann Annotater) {
+	doc[name] = val
+	var walk func(v interface{}, parent string)
+	walk = func(v interface{}, parent string) {
+		switch vv := v.(type) {
+		case map[string]interface{}:
+			for k, e := range vv {
+				if ann != nil {
+					tags.add(parent, k, ann.Annotate(k))
+				}
+				walk(e, bodyStructName(parent, k))
+			}
+		case []interface{}:
+			for _, e := range vv {
+				walk(e, parent)
+			}
+		}
+	}
+	walk(val, name)
+}
+
+// bodyStructName returns the name of the struct of the object of key inside
+// the struct parent of a body. The endpoint types of the root are named after
+// their key and the objects inside a body after the endpoint type and the keys
+// leading to them, e.g. get_users_response_data, so that the bodies of
+// different endpoints sharing a key such as data never share a struct.
+func bodyStructName(parent, key string) string {
+	if parent == rootName {
+		return key
+	}
+	return parent + "_" + key
+}
+
+// routeName returns the name of the endpoint of a request, made of the method
+// and the words of the path. The api and version prefixes of the path are
+// dropped and a trailing id becomes "by id".
+func routeName(method, rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	words := []string{strings.ToLower(method)}
+	segs := strings.Split(strings.Trim(u.Path, "/"), "/")
+	prefix := true
+	for i, s := range segs {
+		if prefix && (strings.EqualFold(s, "api") || harVersion(s)) {
+			continue
+		}
+		prefix = false
+		if harParam(s) {
+			if i == len(segs)-1 {
+				words = append(words, "by", "id")
+			}
+			continue
+		}
+		if s != "" {
+			words = append(words, s)
+		}
+	}
+	return strings.Join(words, "_"), nil
+}
+
+// harVersion checks if a path segment is an api version, e.g. v2
+func harVersion(s string) bool {
+	if len(s) < 2 || (s[0] != 'v' && s[0] != 'V') {
+		return false
+	}
+	for _, r := range s[1:] {
+		if !unicode.IsDigit(r) && r != '.' {
+			return false
+		}
+	}
+	return true
+}
+
+// harParam checks if a path segment is a parameter rather than a part of the
+// route: a number, a uuid, a long hexadecimal id or a template like {id}
+func harParam(s string) bool {
+	if s == "" {
+		return false
+	}
+	if strings.HasPrefix(s, "{") || strings.HasPrefix(s, ":") {
+		return true
+	}
+	digits, hex := true, true
+	for _, r := range s {
+		if !unicode.IsDigit(r) {
+			digits = false
+		}
+		if !strings.ContainsRune("0123456789abcdefABCDEF-", r) {
+			hex = false
+		}
+	}
+	return digits || (hex && len(s) >= 16)
+}
+
+// paramsMap converts query or form parameters into a map, the way the Form
+// decoder does
+func paramsMap(pairs []harPair) (map[string]interface{}, error) {
+	doc := make(map[string]interface{})
+	for _, p := range pairs {
+		path, err := formPath(p.Name)
+		if err != nil {
+			return nil, err
+		}
+		if err = setForm(doc, path, p.Value); err != nil {
+			return nil, fmt.Errorf("%s: %v", p.Name, err)
+		}
+	}
+	return formValues(doc).(map[string]interface{}), nil
+}
+
+// decodeBody decodes a body with the decoder of its content type. It returns a
+// nil value for empty bodies and bodies that cannot be decoded, which are
+// common in captures and skipped.
+func decodeBody(mimeType, text, encoding string) (interface{}, Annotater) {
+	if encoding == "base64" {
+		b, err := base64.StdEncoding.DecodeString(text)
+		if err != nil {
+			log.Println("Skipping body with invalid base64", err)
+			return nil, nil
+		}
+		text = string(b)
+	}
+	format := FormatOfContentType(mimeType)
+	if strings.TrimSpace(text) == "" || format == "" {
+		return nil, nil
+	}
+	dec, err := newDecoder(format, "", strings.NewReader(text))
+	if err != nil {
+		log.Println("Skipping body", err)
+		return nil, nil
+	}
+	data, err := dec.Decode()
+	if err != nil {
+		log.Println("Skipping body that cannot be decoded", err)
+		return nil, nil
+	}
+	ann, _ := dec.(Annotater)
+	return decodedValue(data), ann
+}
+
+// decodedValue returns the decoded data as a single value
+func decodedValue(data DecodedData) interface{} {
+	switch {
+	case data.mapData != nil:
+		return data.mapData
+	case data.sliceData != nil:
+		return data.sliceData
+	case len(data.documents) > 0:
+		sl := make([]interface{}, 0, len(data.documents))
+		for _, d := range data.documents {
+			sl = append(sl, decodedValue(d))
+		}
+		return sl
+	}
+	return nil
+}
+
+// AnnotateKey annotates a field with the tag of the decoder of its body, or
+// no tag for the endpoint types of the root
+func (h *HAR) AnnotateKey(parent, name string) string {
+	return h.tags[parent][name]
+}
+
+// StructName names the structs of the objects of the bodies after their
+// endpoint type
+func (h *HAR) StructName(parent, key string) string {
+	return bodyStructName(parent, key)
+}
+
+// Annotate a field name with its json tag
+func (h *HAR) Annotate(name string) string {
+	return "json:" + name
+}
+
+// Source of this HAR instance, the file name or empty if read from a reader
+func (h *HAR) Source() string {
+	return h.File
+}
@@ -0,0 +1,160 @@
+package togo
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRouteName(t *testing.T) {
+	tests := []struct {
+		tc     string
+		method string
+		url    string
+		exp    string
+	}{
+		{"Collection", "GET", "https://x.com/users?page=1", "get_users"},
+		{"Item", "GET", "https://x.com/api/v2/users/42", "get_users_by_id"},
+		{"Nested", "post", "https://x.com/users/42/orders", "post_users_orders"},
+		{"UUID", "DELETE", "https://x.com/sessions/3f2b8c1e-9d4a-4b6e-8f1a-2c3d4e5f6a7b", "delete_sessions_by_id"},
+		{"Template", "PUT", "https://x.com/items/{id}", "put_items_by_id"},
+		{"Root", "GET", "https://x.com/", "get"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tc, func(t *testing.T) {
+			route, err := routeName(tt.method, tt.url)
+			if err != nil {
+				t.Fatalf("TC: %s: Did not expect error but got %+v", tt.tc, err)
+			}
+			if route != tt.exp {
+				t.Errorf("TC: %s: Expected %s but got %s", tt.tc, tt.exp, route)
+			}
+		})
+	}
+}
+
+func TestHAR_Decode(t *testing.T) {
+	entry := func(method, url, query, post, status, resp string) string {
+		return `{"request": {"method": "` + method + `", "url": "` + url + `", "queryString": [` + query +
+			`]` + post + `}, "response": {"status": ` + status + `, "content": ` + resp + `}}`
+	}
+	har := func(entries ...string) string {
+		return `{"log": {"entries": [` + strings.Join(entries, ",") + `]}}`
+	}
+	tests := []struct {
+		tc     string
+		data   string
+		exp    map[string]interface{}
+		expErr bool
+	}{
+		{"Response", har(entry("GET", "https://x.com/users/1", "", "", "200",
+			`{"mimeType": "application/json", "text": "{\"id\": 1}"}`)), map[string]interface{}{
+			"get_users_by_id_response": map[string]interface{}{"id": 1.0}}, false},
+		{"Base64 Response", har(entry("GET", "https://x.com/users", "", "", "200",
+			`{"mimeType": "application/json", "encoding": "base64", "text": "W3siaWQiOiAxfV0="}`)), map[string]interface{}{
+			"get_users_response": []interface{}{map[string]interface{}{"id": 1.0}}}, false},
+		{"Query And Request", har(entry("POST", "https://x.com/orders?dry=true", `{"name": "dry", "value": "true"}`,
+			`, "postData": {"mimeType": "application/json", "text": "{\"qty\": 2}"}`, "201",
+			`{"mimeType": "text/html", "text": "<p>ok</p>"}`)), map[string]interface{}{
+			"post_orders_query":   map[string]interface{}{"dry": true},
+			"post_orders_request": map[string]interface{}{"qty": 2.0}}, false},
+		{"Form Params", har(entry("POST", "https://x.com/login", "",
+			`, "postData": {"mimeType": "application/x-www-form-urlencoded", "params": [{"name": "user", "value": "x"}]}`,
+			"302", `{}`)), map[string]interface{}{
+			"post_login_request": map[string]interface{}{"user": "x"}}, false},
+		{"Failed Response Skipped", har(entry("GET", "https://x.com/users", `{"name": "q", "value": "a"}`, "", "500",
+			`{"mimeType": "application/json", "text": "{\"error\": \"x\"}"}`)), map[string]interface{}{
+			"get_users_query": map[string]interface{}{"q": "a"}}, false},
+		{"Nothing To Decode", har(entry("GET", "https://x.com/app.js", "", "", "200",
+			`{"mimeType": "application/javascript", "text": "alert(1)"}`)), nil, true},
+		{"Not A HAR", `[1, 2]`, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tc, func(t *testing.T) {
+			dd, err := NewHARBytes([]byte(tt.data)).Decode()
+			if tt.expErr {
+				if err == nil {
+					t.Errorf("TC: %s: Expected error but did not get any error", tt.tc)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("TC: %s: Did not expect error but got %+v", tt.tc, err)
+			}
+			if !reflect.DeepEqual(dd.mapData, tt.exp) {
+				t.Errorf("TC: %s: Expected %+v but got %+v", tt.tc, tt.exp, dd.mapData)
+			}
+		})
+	}
+}
+
+func TestParse_HAR(t *testing.T) {
+	dec, err := NewFileDecoder("", "samples/har/partner.har")
+	if err != nil {
+		t.Fatalf("NewFileDecoder failed: %+v", err)
+	}
+	if err = Parse(dec); err != nil {
+		t.Fatalf("Parse failed: %+v", err)
+	}
+	var buf bytes.Buffer
+	if err = WriteStructs(&buf); err != nil {
+		t.Fatalf("WriteStructs failed: %+v", err)
+	}
+	out := buf.String()
+	for _, exp := range []string{
+		"\tGetUsersResponse []GetUsersResponse\n",
+		"\tGetUsersQuery GetUsersQuery\n",
+		"\tGetUsersByIDResponse GetUsersByIDResponse\n",
+		"\tPostOrdersRequest PostOrdersRequest\n",
+		"\tPostOrdersResponse PostOrdersResponse\n",
+		"\tPostLoginRequest PostLoginRequest\n",
+		"type GetUsersResponse struct {",
+		"\tAdmin bool `json:\"admin\"`",
+		"type GetUsersQuery struct {",
+		"\tPerPage int `query:\"per_page\"`",
+		"\tSort string `query:\"sort\"`",
+		"\tAddress GetUsersByIDResponseAddress `json:\"address\"`",
+		"\tItems []PostOrdersRequestItems `json:\"items\"`",
+		"\tOrderID string `json:\"order_id\"`",
+		"\tRemember bool `form:\"remember\"`",
+	} {
+		if !strings.Contains(out, exp) {
+			t.Errorf("Expected %q in the structs but got:\n%s", exp, out)
+		}
+	}
+	if strings.Contains(out, "Error") {
+		t.Errorf("Did not expect the failed response in the structs:\n%s", out)
+	}
+}
+
+func TestParse_HARSharedKeys(t *testing.T) {
+	entry := func(method, url, mime, text string) string {
+		return `{"request": {"method": "` + method + `", "url": "` + url + `", "queryString": [], "postData": ` +
+			`{"mimeType": "` + mime + `", "text": "` + text + `"}}, "response": {"status": 200, "content": ` +
+			`{"mimeType": "application/json", "text": "` + text + `"}}}`
+	}
+	har := `{"log": {"entries": [` +
+		entry("POST", "https://x.com/users", "application/json", `{\"data\": {\"id\": 1}}`) + `,` +
+		entry("POST", "https://x.com/orders", "application/x-www-form-urlencoded", `data[id]=abc`) +
+		`]}}`
+	if err := Parse(NewHARBytes([]byte(har))); err != nil {
+		t.Fatalf("Parse failed: %+v", err)
+	}
+	var buf bytes.Buffer
+	if err := WriteStructs(&buf); err != nil {
+		t.Fatalf("WriteStructs failed: %+v", err)
+	}
+	out := buf.String()
+	for _, exp := range []string{
+		"\tData PostUsersRequestData `json:\"data\"`",
+		"\tData PostUsersResponseData `json:\"data\"`",
+		"\tData PostOrdersRequestData `form:\"data\"`",
+		"type PostUsersRequestData struct {\n\tID float64 `json:\"id\"`",
+		"type PostOrdersRequestData struct {\n\tID string `form:\"id\"`",
+	} {
+		if !strings.Contains(out, exp) {
+			t.Errorf("Expected %q in the structs but got:\n%s", exp, out)
+		}
+	}
+}
@@ -0,0 +1,219 @@
+package togo
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultHTTPTimeout is how long an HTTP decoder waits for the whole
+// response unless told otherwise
+const DefaultHTTPTimeout = 30 * time.Second
+
+// contentFormats maps media types onto the format of the data
+var contentFormats = map[string]string{
+	"application/json":                  FormatJSON,
+	"text/json":                         FormatJSON,
+	"application/x-ndjson":              FormatNDJSON,
+	"application/jsonl":                 FormatNDJSON,
+	"application/json-seq":              FormatNDJSON,
+	"application/json5":                 FormatJSON5,
+	"application/msgpack":               FormatMsgPack,
+	"application/x-msgpack":             FormatMsgPack,
+	"application/cbor":                  FormatCBOR,
+	"application/yaml":                  FormatYAML,
+	"application/x-yaml":                FormatYAML,
+	"text/yaml":                         FormatYAML,
+	"text/x-yaml":                       FormatYAML,
+	"application/toml":                  FormatTOML,
+	"application/xml":                   FormatXML,
+	"text/xml":                          FormatXML,
+	"text/csv":                          FormatCSV,
+	"text/tab-separated-values":         FormatTSV,
+	"application/x-www-form-urlencoded": FormatForm,
+}
+
+// FormatOfContentType returns the format of data of the content type, e.g.
+// "application/json; charset=utf-8". Structured syntax suffixes such as
+// "application/problem+json" are understood as well. It returns an empty
+// string if the content type is not known.
+func FormatOfContentType(ct string) string {
+	mt, _, err := mime.ParseMediaType(ct)
+	if err != nil {
+		return ""
+	}
+	if f, ok := contentFormats[mt]; ok {
+		return f
+	}
+	if idx := strings.LastIndex(mt, "+"); idx >= 0 {
+		return contentFormats["application/"+mt[idx+1:]]
+	}
+	return ""
+}
+
+// HTTP type structure to convert the response of an HTTP request to go
+// struct. The response is decoded by the decoder of the format told by its
+// Content-Type, which also annotates, documents and names the fields and
+// structs, or builds them if the response describes types.
+type HTTP struct {
+	URL    string
+	Method string
+	Header http.Header
+	Body   []byte
+	// Token is sent as a bearer token in the Authorization header
+	Token string
+	// Format is used when the Content-Type of the response does not tell the
+	// format. If both are known they must agree.
+	Format string
+	// Timeout of the whole request, DefaultHTTPTimeout if not set. It is not
+	// used if Client is given.
+	Timeout time.Duration
+	Client  *http.Client
+
+	dec Decoder
+}
+
+// NewHTTP creates an HTTP decoder doing a GET of the url
+func NewHTTP(url string) *HTTP {
+	return &HTTP{URL: url, Method: http.MethodGet, Header: make(http.Header)}
+}
+
+// Decode the response of the request of this HTTP instance into decodedData
+func (h *HTTP) Decode() (DecodedData, error) {
+	if err := h.fetch(); err != nil {
+		return DecodedData{}, err
+	}
+	return h.dec.Decode()
+}
+
+// Build the structs of the response into the caches and return the root
+// struct. Responses describing types, e.g. a schema, are built by the decoder
+// of the response and the others are decoded and parsed as usual.
+func (h *HTTP) Build(tr tracker) (*GoStruct, error) {
+	if err := h.fetch(); err != nil {
+		return nil, err
+	}
+	if sb, ok := h.dec.(StructBuilder); ok {
+		return sb.Build(tr)
+	}
+	if mt, ok := h.dec.(MixedTyper); ok {
+		tr.mixed = mt.MixedTypes()
+	}
+	gs, _, err := parseData(h.dec, tr)
+	return gs, err
+}
+
+// fetch does the request and creates the decoder of the response
+func (h *HTTP) fetch() error {
+	method := h.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	req, err := http.NewRequest(method, h.URL, bytes.NewReader(h.Body))
+	if err != nil {
+		log.Println("Error while creating request", err)
+		return err
+	}
+	for k, vs := range h.Header {
+		for _, v := range vs {
+			req.Header.Add(k, v)
+		}
+	}
+	if h.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+h.Token)
+	}
+
+	client := h.Client
+	if client == nil {
+		timeout := h.Timeout
+		if timeout == 0 {
+			timeout = DefaultHTTPTimeout
+		}
+		client = &http.Client{Timeout: timeout}
+	}
+	log.Printf("Requesting %s %s\n", method, h.URL)
+	resp, err := client.Do(req)
+	if err != nil {
+		log.Println("Error while requesting", err)
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		log.Println("Error while reading response", err)
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%s %s responded with %s", method, h.URL, resp.Status)
+	}
+
+	format, err := h.format(resp.Header.Get("Content-Type"))
+	if err != nil {
+		return err
+	}
+	h.dec, err = newDecoder(format, h.URL, bytes.NewReader(body))
+	return err
+}
+
+// format of the response with the content type ct
+func (h *HTTP) format(ct string) (string, error) {
+	cf := FormatOfContentType(ct)
+	switch {
+	case cf == "" && h.Format != "":
+		return h.Format, nil
+	case cf == "":
+		if u, err := url.Parse(h.URL); err == nil && FormatOf(u.Path) != "" {
+			return FormatOf(u.Path), nil
+		}
+		return "", UnsupportedFormat{format: ct}
+	case h.Format != "" && !strings.EqualFold(h.Format, cf):
+		return "", fmt.Errorf("Expected %s from %s but the content type is %s",
+			h.Format, h.URL, ct)
+	default:
+		return cf, nil
+	}
+}
+
+// Source of this HTTP instance, the URL
+func (h *HTTP) Source() string {
+	return h.URL
+}
+
+// Annotate a field name with the tag of the decoder of the response
+func (h *HTTP) Annotate(name string) string {
+	if ann, ok := h.dec.(Annotater); ok {
+		return ann.Annotate(name)
+	}
+	return "json:" + name
+}
+
+// AnnotateKey annotates a field the way the decoder of the response does
+func (h *HTTP) AnnotateKey(parent, name string) string {
+	if ka, ok := h.dec.(KeyAnnotater); ok {
+		return ka.AnnotateKey(parent, name)
+	}
+	return h.Annotate(name)
+}
+
+// Comment returns the doc comment the decoder of the response has for a key
+func (h *HTTP) Comment(parent, name string) string {
+	if cmt, ok := h.dec.(Commenter); ok {
+		return cmt.Comment(parent, name)
+	}
+	return ""
+}
+
+// StructName names a struct the way the decoder of the response does, after
+// its key unless the decoder names its structs
+func (h *HTTP) StructName(parent, key string) string {
+	if nm, ok := h.dec.(StructNamer); ok {
+		return nm.StructName(parent, key)
+	}
+	return key
+}
@@ -0,0 +1,169 @@
+package togo
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatOfContentType(t *testing.T) {
+	tests := []struct {
+		tc     string
+		ct     string
+		format string
+	}{
+		{"JSON", "application/json", FormatJSON},
+		{"JSON With Charset", "application/json; charset=utf-8", FormatJSON},
+		{"Suffix", "application/problem+json", FormatJSON},
+		{"YAML", "application/x-yaml", FormatYAML},
+		{"XML Suffix", "application/soap+xml", FormatXML},
+		{"CSV", "text/csv", FormatCSV},
+		{"Unknown", "text/html", ""},
+		{"Invalid", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tc, func(t *testing.T) {
+			if got := FormatOfContentType(tt.ct); got != tt.format {
+				t.Errorf("TC: %s: Expected %q but got %q", tt.tc, tt.format, got)
+			}
+		})
+	}
+}
+
+func TestHTTP_Decode(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/users":
+			if r.Header.Get("Authorization") != "Bearer s3cret" || r.Header.Get("X-Team") != "togo" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			w.Write([]byte(`{"id": 1, "name": "togo"}`))
+		case "/echo":
+			body, _ := ioutil.ReadAll(r.Body)
+			w.Header().Set("Content-Type", r.Header.Get("Content-Type"))
+			w.Write(body)
+		case "/config.yaml":
+			w.Header().Set("Content-Type", "text/plain")
+			w.Write([]byte("name: togo\n"))
+		case "/page":
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte("<html></html>"))
+		case "/slow":
+			time.Sleep(200 * time.Millisecond)
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"name": "togo"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		tc     string
+		setup  func(h *HTTP)
+		path   string
+		key    string
+		expErr bool
+	}{
+		{"Get With Auth", func(h *HTTP) {
+			h.Token = "s3cret"
+			h.Header.Set("X-Team", "togo")
+		}, "/users", "name", false},
+		{"Missing Auth", func(h *HTTP) {}, "/users", "", true},
+		{"Post", func(h *HTTP) {
+			h.Method = http.MethodPost
+			h.Header.Set("Content-Type", "application/xml")
+			h.Body = []byte("<doc><name>togo</name></doc>")
+		}, "/echo", "name", false},
+		{"Format From Path", func(h *HTTP) {}, "/config.yaml", "name", false},
+		{"Format Given", func(h *HTTP) { h.Format = FormatYAML }, "/config.yaml", "name", false},
+		{"Format Mismatch", func(h *HTTP) {
+			h.Format = FormatYAML
+			h.Token = "s3cret"
+			h.Header.Set("X-Team", "togo")
+		}, "/users", "", true},
+		{"Unsupported Content Type", func(h *HTTP) {}, "/page", "", true},
+		{"Not Found", func(h *HTTP) {}, "/missing", "", true},
+		{"Timeout", func(h *HTTP) { h.Timeout = 50 * time.Millisecond }, "/slow", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tc, func(t *testing.T) {
+			h := NewHTTP(srv.URL + tt.path)
+			tt.setup(h)
+			dd, err := h.Decode()
+			if tt.expErr {
+				if err == nil {
+					t.Errorf("TC: %s: Expected error but did not get any error", tt.tc)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("TC: %s: Did not expect error but got %+v", tt.tc, err)
+			}
+			if len(dd.documents) > 0 {
+				dd = dd.documents[0]
+			}
+			if dd.mapData[tt.key] != "togo" {
+				t.Errorf("TC: %s: Expected %s to be decoded, got %+v", tt.tc, tt.key, dd)
+			}
+			if h.Source() != srv.URL+tt.path {
+				t.Errorf("TC: %s: Expected source %s but got %s", tt.tc, srv.URL+tt.path, h.Source())
+			}
+		})
+	}
+}
+
+func TestParse_HTTP(t *testing.T) {
+	bodies := map[string]string{
+		"/settings.json5": "{\n  // Size of a tab\n  tabSize: 4,\n}\n",
+		"/service.env":    "DB_HOST=db\nDB_PORT=5432\n",
+		"/capture.har": `{"log": {"entries": [{"request": {"method": "GET", "url": "https://x.com/users",
+			"queryString": [{"name": "per_page", "value": "5"}]}, "response": {"status": 200,
+			"content": {"mimeType": "application/json", "text": "{\"data\": {\"id\": 1}}"}}}]}}`,
+		"/order.schema.json": `{"title": "Order", "type": "object", "required": ["id"],
+			"properties": {"id": {"type": "integer"}}}`,
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte(bodies[r.URL.Path]))
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		tc     string
+		path   string
+		format string
+		exp    []string
+	}{
+		{"Comment", "/settings.json5", FormatJSON5, []string{"\t// Size of a tab\n\tTabSize float64 `json:\"tabSize\"`"}},
+		{"AnnotateKey", "/service.env", FormatEnv, []string{"\tHost string `env:\"DB_HOST\"`"}},
+		{"StructName", "/capture.har", FormatHAR, []string{"\tPerPage int `query:\"per_page\"`",
+			"\tData GetUsersResponseData `json:\"data\"`"}},
+		{"Build", "/order.schema.json", FormatJSONSchema, []string{"type Order struct {",
+			"\tID int `json:\"id\"`"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tc, func(t *testing.T) {
+			h := NewHTTP(srv.URL + tt.path)
+			h.Format = tt.format
+			if err := Parse(h); err != nil {
+				t.Fatalf("TC: %s: Parse failed: %+v", tt.tc, err)
+			}
+			var buf bytes.Buffer
+			if err := WriteStructs(&buf); err != nil {
+				t.Fatalf("TC: %s: WriteStructs failed: %+v", tt.tc, err)
+			}
+			for _, exp := range tt.exp {
+				if !strings.Contains(buf.String(), exp) {
+					t.Errorf("TC: %s: Expected %q in the structs but got:\n%s", tt.tc, exp, buf.String())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package togo
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// inferScalar guesses the FieldDT of a scalar that is only available as
+// text, like a CSV cell or an INI value. Numbers with leading zeros are kept
+// as String since they usually are codes (zip codes, ids) and not numbers.
+func inferScalar(s string) FieldDT {
+	if strings.EqualFold(s, "true") || strings.EqualFold(s, "false") {
+		return Bool
+	}
+	if len(s) > 1 && s[0] == '0' && s[1] != '.' {
+		return String
+	}
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		if i >= math.MinInt32 && i <= math.MaxInt32 {
+			return Int
+		}
+		return Int64
+	}
+	// Inf and NaN are text rather than numbers in the inputs of scalars
+	if f, err := strconv.ParseFloat(s, 64); err == nil && !math.IsInf(f, 0) && !math.IsNaN(f) &&
+		strings.ContainsAny(s[:1], "+-.0123456789") {
+		return Float64
+	}
+	return String
+}
+
+// widen returns the FieldDT that can hold values of both the types.
+// Numeric types widen to the larger one and any other mix becomes String.
+func widen(a, b FieldDT) FieldDT {
+	if a == Initial || a == b {
+		return b
+	}
+	if b == Initial {
+		return a
+	}
+	if a.numeric() && b.numeric() {
+		if a > b {
+			return a
+		}
+		return b
+	}
+	return String
+}
+
+// convertScalar converts the text of a scalar into a go value of type dt.
+// The text must be valid for dt, as established by inferScalar.
+func convertScalar(s string, dt FieldDT) interface{} {
+	switch dt {
+	case Bool:
+		return strings.EqualFold(s, "true")
+	case Int:
+		i, _ := strconv.Atoi(s)
+		return i
+	case Int64:
+		i, _ := strconv.ParseInt(s, 10, 64)
+		return i
+	case Float64:
+		f, _ := strconv.ParseFloat(s, 64)
+		return f
+	default:
+		return s
+	}
+}
+
+// scalarValue converts the text of a scalar into a go value of its inferred type
+func scalarValue(s string) interface{} {
+	return convertScalar(s, inferScalar(s))
+}
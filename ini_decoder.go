@@ -0,0 +1,342 @@
+package togo
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// INI type structure to convert INI files to go struct. Every section becomes
+// a nested struct, with dotted section names ([server.http]) nesting further.
+// Values go through scalar inference unless they are quoted, and keys that
+// are repeated in a section become slices. The comment lines right before a
+// key or section become the doc comment of its field.
+type INI struct {
+	File   string
+	reader io.Reader
+
+	comments keyTexts
+}
+
+// NewINIReader creates an INI decoder reading from r instead of a file.
+// The reader is consumed by the first Decode.
+func NewINIReader(r io.Reader) *INI {
+	return &INI{reader: r}
+}
+
+// NewINIBytes creates an INI decoder for the in-memory data
+func NewINIBytes(b []byte) *INI {
+	return NewINIReader(bytes.NewReader(b))
+}
+
+// Decode this INI instance into decodedData
+func (i *INI) Decode() (DecodedData, error) {
+
+	dd := new(DecodedData)
+	f, err := open(i.File, i.reader)
+	if err != nil {
+		log.Println("Error while reading file", err)
+		return *dd, err
+	}
+	defer f.Close()
+
+	i.comments = make(keyTexts)
+	doc := make(map[string]interface{})
+	sec, parent := doc, rootName
+	var pending []string
+	sc := bufio.NewScanner(f)
+	for line := 1; sc.Scan(); line++ {
+		l := strings.TrimSpace(sc.Text())
+		switch {
+		case l == "":
+			pending = nil
+			continue
+		case l[0] == ';' || l[0] == '#':
+			pending = append(pending, strings.TrimSpace(l[1:]))
+			continue
+		case l[0] == '[':
+			end := strings.IndexByte(l, ']')
+			if end < 0 {
+				return *dd, fmt.Errorf("line %d: section %s is not closed", line, l)
+			}
+			path := strings.Split(strings.TrimSpace(l[1:end]), ".")
+			if sec, err = nestedMap(doc, path, ""); err != nil {
+				return *dd, fmt.Errorf("line %d: %v", line, err)
+			}
+			name := path[len(path)-1]
+			if len(pending) > 0 {
+				i.comments.add(keyParent(path), name, strings.Join(pending, "\n"))
+			}
+			parent, pending = name, nil
+			continue
+		}
+
+		sep := strings.IndexAny(l, "=:")
+		if sep <= 0 {
+			return *dd, fmt.Errorf("line %d: expected key = value but found %s", line, l)
+		}
+		key := strings.TrimSpace(l[:sep])
+		val := iniValue(strings.TrimSpace(l[sep+1:]))
+		switch ex := sec[key].(type) {
+		case nil:
+			sec[key] = val
+		case []interface{}:
+			sec[key] = append(ex, val)
+		default:
+			sec[key] = []interface{}{ex, val}
+		}
+		if len(pending) > 0 {
+			i.comments.add(parent, key, strings.Join(pending, "\n"))
+		}
+		pending = nil
+	}
+	if err = sc.Err(); err != nil {
+		log.Println("Error while reading lines", err)
+		return *dd, err
+	}
+	dd.mapData = doc
+	return *dd, nil
+}
+
+// iniValue returns the value of an INI key. A quoted value is a string,
+// anything else is inferred after removing an inline comment.
+func iniValue(v string) interface{} {
+	if len(v) > 1 && (v[0] == '"' || v[0] == '\'') {
+		if end := strings.IndexByte(v[1:], v[0]); end >= 0 {
+			return v[1 : end+1]
+		}
+	}
+	for i := 1; i < len(v); i++ {
+		if (v[i] == ';' || v[i] == '#') && unicode.IsSpace(rune(v[i-1])) {
+			v = strings.TrimSpace(v[:i])
+			break
+		}
+	}
+	return scalarValue(v)
+}
+
+// Comment returns the comment documenting the key name of the section parent
+func (i *INI) Comment(parent, name string) string {
+	return i.comments[parent][name]
+}
+
+// Source of this INI instance, the file name or empty if read from a reader
+func (i *INI) Source() string {
+	return i.File
+}
+
+// Annotate a field name with its ini tag
+func (i *INI) Annotate(name string) string {
+	return "ini:" + name
+}
+
+// Properties type structure to convert Java .properties files to go struct.
+// Dotted keys (a.b.c=value) are expanded into nested maps, so that every
+// prefix becomes a nested struct, and values go through scalar inference.
+// A key that is also the prefix of other keys, as in a=1 and a.b=2, keeps its
+// value as the field Value of its struct, i.e. as if it were a.value.
+// The comment lines right before a key become the doc comment of its field.
+type Properties struct {
+	File   string
+	reader io.Reader
+
+	comments keyTexts
+}
+
+// NewPropertiesReader creates a Properties decoder reading from r instead of
+// a file. The reader is consumed by the first Decode.
+func NewPropertiesReader(r io.Reader) *Properties {
+	return &Properties{reader: r}
+}
+
+// NewPropertiesBytes creates a Properties decoder for the in-memory data
+func NewPropertiesBytes(b []byte) *Properties {
+	return NewPropertiesReader(bytes.NewReader(b))
+}
+
+// Decode this Properties instance into decodedData
+func (p *Properties) Decode() (DecodedData, error) {
+
+	dd := new(DecodedData)
+	f, err := open(p.File, p.reader)
+	if err != nil {
+		log.Println("Error while reading file", err)
+		return *dd, err
+	}
+	defer f.Close()
+
+	p.comments = make(keyTexts)
+	doc := make(map[string]interface{})
+	var pending []string
+	set := func(logical string) error {
+		key, val := propertiesPair(logical)
+		path := strings.Split(key, ".")
+		sec, err := nestedMap(doc, path[:len(path)-1], propertiesValue)
+		if err != nil {
+			return err
+		}
+		name, parent := path[len(path)-1], keyParent(path)
+		if mp, ok := sec[name].(map[string]interface{}); ok {
+			sec, name, parent = mp, propertiesValue, name
+		}
+		sec[name] = scalarValue(val)
+		if len(pending) > 0 {
+			p.comments.add(parent, name, strings.Join(pending, "\n"))
+		}
+		pending = nil
+		return nil
+	}
+
+	var logical string
+	sc := bufio.NewScanner(f)
+	line := 1
+	for ; sc.Scan(); line++ {
+		l := strings.TrimLeftFunc(sc.Text(), unicode.IsSpace)
+		if logical == "" {
+			if l == "" {
+				pending = nil
+				continue
+			}
+			if l[0] == '#' || l[0] == '!' {
+				pending = append(pending, strings.TrimSpace(l[1:]))
+				continue
+			}
+		}
+		// A line ending in an odd number of backslashes continues on the next
+		trailing := len(l) - len(strings.TrimRight(l, "\\"))
+		if trailing%2 == 1 {
+			logical += l[:len(l)-1]
+			continue
+		}
+		if err = set(logical + l); err != nil {
+			return *dd, fmt.Errorf("line %d: %v", line, err)
+		}
+		logical = ""
+	}
+	if logical != "" {
+		if err = set(logical); err != nil {
+			return *dd, fmt.Errorf("line %d: %v", line, err)
+		}
+	}
+	if err = sc.Err(); err != nil {
+		log.Println("Error while reading lines", err)
+		return *dd, err
+	}
+	dd.mapData = doc
+	return *dd, nil
+}
+
+// propertiesPair splits a logical line of a .properties file into its key
+// and value. The key ends at the first unescaped '=', ':' or white space.
+func propertiesPair(l string) (string, string) {
+	end := len(l)
+	for i := 0; i < len(l); i++ {
+		if l[i] == '\\' {
+			i++
+			continue
+		}
+		if l[i] == '=' || l[i] == ':' || unicode.IsSpace(rune(l[i])) {
+			end = i
+			break
+		}
+	}
+	key, rest := l[:end], strings.TrimLeftFunc(l[end:], unicode.IsSpace)
+	if rest != "" && (rest[0] == '=' || rest[0] == ':') {
+		rest = strings.TrimLeftFunc(rest[1:], unicode.IsSpace)
+	}
+	return propertiesUnescape(key), propertiesUnescape(rest)
+}
+
+// propertiesUnescape resolves the escapes of keys and values of .properties
+func propertiesUnescape(s string) string {
+	if !strings.Contains(s, "\\") {
+		return s
+	}
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i == len(s)-1 {
+			sb.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 't':
+			sb.WriteByte('\t')
+		case 'n':
+			sb.WriteByte('\n')
+		case 'r':
+			sb.WriteByte('\r')
+		case 'f':
+			sb.WriteByte('\f')
+		case 'u':
+			if i+5 <= len(s) {
+				if r, err := strconv.ParseUint(s[i+1:i+5], 16, 32); err == nil {
+					sb.WriteRune(rune(r))
+					i += 4
+					continue
+				}
+			}
+			sb.WriteByte('u')
+		default:
+			sb.WriteByte(s[i])
+		}
+	}
+	return sb.String()
+}
+
+// Comment returns the comment documenting the key name with the prefix parent
+func (p *Properties) Comment(parent, name string) string {
+	return p.comments[parent][name]
+}
+
+// Source of this Properties instance, the file name or empty if read from a reader
+func (p *Properties) Source() string {
+	return p.File
+}
+
+// Annotate a field name with its properties tag
+func (p *Properties) Annotate(name string) string {
+	return "properties:" + name
+}
+
+// propertiesValue is the key of the value of a Properties key that is also
+// the prefix of other keys
+const propertiesValue = "value"
+
+// nestedMap returns the map found following the path of keys from doc,
+// creating the maps missing along the way. A value found along the way is
+// moved into the map under the key keep, or is an error if keep is empty.
+func nestedMap(doc map[string]interface{}, path []string, keep string) (map[string]interface{}, error) {
+	for idx, key := range path {
+		switch v := doc[key].(type) {
+		case nil:
+			mp := make(map[string]interface{})
+			doc[key] = mp
+			doc = mp
+		case map[string]interface{}:
+			doc = v
+		default:
+			if keep == "" {
+				return nil, fmt.Errorf("%s is a value and a prefix of other keys",
+					strings.Join(path[:idx+1], "."))
+			}
+			mp := map[string]interface{}{keep: v}
+			doc[key] = mp
+			doc = mp
+		}
+	}
+	return doc, nil
+}
+
+// keyParent returns the name of the struct holding the last key of the path
+func keyParent(path []string) string {
+	if len(path) < 2 {
+		return rootName
+	}
+	return path[len(path)-2]
+}
@@ -0,0 +1,135 @@
+package togo
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestINI_Decode(t *testing.T) {
+	tests := []struct {
+		tc     string
+		data   string
+		exp    map[string]interface{}
+		expErr bool
+	}{
+		{"Root Keys", "a = 1\nb: x\n", map[string]interface{}{"a": 1, "b": "x"}, false},
+		{"Sections", "[db]\nport = 5432\n[db.pool]\nsize = 10\n", map[string]interface{}{
+			"db": map[string]interface{}{"port": 5432, "pool": map[string]interface{}{"size": 10}}}, false},
+		{"Quoted Values", "a = \"8080\" ; port\nb = 'x; y'\n", map[string]interface{}{"a": "8080", "b": "x; y"}, false},
+		{"Inline Comments", "a = 1 ; one\nb = a#b\n", map[string]interface{}{"a": 1, "b": "a#b"}, false},
+		{"Repeated Keys", "a = 1\na = 2\na = 3\n", map[string]interface{}{"a": []interface{}{1, 2, 3}}, false},
+		{"Empty Value", "a =\n", map[string]interface{}{"a": ""}, false},
+		{"Unclosed Section", "[db\n", nil, true},
+		{"Missing Separator", "just a line\n", nil, true},
+		{"Section Over Value", "db = 1\n[db]\n", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tc, func(t *testing.T) {
+			dd, err := NewINIBytes([]byte(tt.data)).Decode()
+			if tt.expErr {
+				if err == nil {
+					t.Errorf("TC: %s: Expected error but did not get any error", tt.tc)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("TC: %s: Did not expect error but got %+v", tt.tc, err)
+			}
+			if !reflect.DeepEqual(dd.mapData, tt.exp) {
+				t.Errorf("TC: %s: Expected %+v but got %+v", tt.tc, tt.exp, dd.mapData)
+			}
+		})
+	}
+}
+
+func TestProperties_Decode(t *testing.T) {
+	tests := []struct {
+		tc     string
+		data   string
+		exp    map[string]interface{}
+		expErr bool
+	}{
+		{"Separators", "a=1\nb: 2\nc 3\nd = x y\n", map[string]interface{}{"a": 1, "b": 2, "c": 3, "d": "x y"}, false},
+		{"Dotted Keys", "db.host=localhost\ndb.port=5432\ndb.pool.size=10\n", map[string]interface{}{
+			"db": map[string]interface{}{"host": "localhost", "port": 5432,
+				"pool": map[string]interface{}{"size": 10}}}, false},
+		{"Comments", "# one\n! two\na=1\n", map[string]interface{}{"a": 1}, false},
+		{"Continuation", "a=x \\\n   y\\\\\nb=2\n", map[string]interface{}{"a": "x y\\", "b": 2}, false},
+		{"Escapes", "a\\:b=c\\td\\u0041\n", map[string]interface{}{"a:b": "c\tdA"}, false},
+		{"Last Key Wins", "a=1\na=true\n", map[string]interface{}{"a": true}, false},
+		{"Value Over Prefix", "a.b=1\na=2\n", map[string]interface{}{
+			"a": map[string]interface{}{"b": 1, "value": 2}}, false},
+		{"Prefix Over Value", "a=2\na.b.c=1\n", map[string]interface{}{
+			"a": map[string]interface{}{"value": 2, "b": map[string]interface{}{"c": 1}}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tc, func(t *testing.T) {
+			dd, err := NewPropertiesBytes([]byte(tt.data)).Decode()
+			if tt.expErr {
+				if err == nil {
+					t.Errorf("TC: %s: Expected error but did not get any error", tt.tc)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("TC: %s: Did not expect error but got %+v", tt.tc, err)
+			}
+			if !reflect.DeepEqual(dd.mapData, tt.exp) {
+				t.Errorf("TC: %s: Expected %+v but got %+v", tt.tc, tt.exp, dd.mapData)
+			}
+		})
+	}
+}
+
+func TestParse_INIAndProperties(t *testing.T) {
+	tests := []struct {
+		tc   string
+		file string
+		exp  []string
+	}{
+		{"INI", "samples/ini/service.ini", []string{
+			"\tDebug bool `ini:\"debug\"`",
+			"\t// Where the service listens\n\tServer Server `ini:\"server\"`",
+			"\t// Port of the HTTP listener\n\tPort int `ini:\"port\"`",
+			"\tTimeout float64 `ini:\"timeout\"`",
+			"\tTLS TLS `ini:\"tls\"`",
+			"\tCert string `ini:\"cert\"`",
+			"\tReplica []string `ini:\"replica\"`",
+		}},
+		{"Properties", "samples/properties/application.properties", []string{
+			"\tApp App `properties:\"app\"`",
+			"\t// Name of the application\n\tName string `properties:\"name\"`",
+			"\tVersion float64 `properties:\"version\"`",
+			"\tPort int `properties:\"port\"`",
+			"\tEnabled bool `properties:\"enabled\"`",
+			"\t// Connection to the database\n\tURL string `properties:\"url\"`",
+			"\tMaxPoolSize int `properties:\"max-pool-size\"`",
+			"\tNewCheckout bool `properties:\"new=checkout\"`",
+			"\t// Log level of every logger\n\tLevel Level `properties:\"level\"`",
+			"\tRoot string `properties:\"root\"`\n\tValue string `properties:\"value\"`",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tc, func(t *testing.T) {
+			dec, err := NewFileDecoder("", tt.file)
+			if err != nil {
+				t.Fatalf("TC: %s: NewFileDecoder failed: %+v", tt.tc, err)
+			}
+			if err = Parse(dec); err != nil {
+				t.Fatalf("TC: %s: Parse failed: %+v", tt.tc, err)
+			}
+			var buf bytes.Buffer
+			if err = WriteStructs(&buf); err != nil {
+				t.Fatalf("TC: %s: WriteStructs failed: %+v", tt.tc, err)
+			}
+			out := buf.String()
+			for _, exp := range tt.exp {
+				if !strings.Contains(out, exp) {
+					t.Errorf("TC: %s: Expected %q in the structs but got:\n%s", tt.tc, exp, out)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,425 @@
+package togo
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// JSON5 type structure to convert JSONC or JSON5 to go struct. Unlike JSON,
+// comments, trailing commas, unquoted keys, single quoted strings and the
+// JSON5 numbers (hexadecimal, Infinity, NaN, ...) are accepted. The comments
+// before a key, or after its value on the same line, document the key and
+// become the doc comment of the generated field.
+type JSON5 struct {
+	File   string
+	reader io.Reader
+
+	comments keyTexts
+}
+
+// NewJSON5Reader creates a JSON5 decoder reading from r instead of a file.
+// The reader is consumed by the first Decode.
+func NewJSON5Reader(r io.Reader) *JSON5 {
+	return &JSON5{reader: r}
+}
+
+// NewJSON5Bytes creates a JSON5 decoder for the in-memory data
+func NewJSON5Bytes(b []byte) *JSON5 {
+	return NewJSON5Reader(bytes.NewReader(b))
+}
+
+// Decode this JSON5 instance into decodedData
+func (j *JSON5) Decode() (DecodedData, error) {
+
+	dd := new(DecodedData)
+	f, err := open(j.File, j.reader)
+	if err != nil {
+		log.Println("Error while reading file", err)
+		return *dd, err
+	}
+	defer f.Close()
+	b, err := ioutil.ReadAll(f)
+	if err != nil {
+		log.Println("Error while reading file", err)
+		return *dd, err
+	}
+
+	p := &json5Parser{data: b, comments: make(keyTexts)}
+	p.space()
+	val, err := p.value(rootName)
+	if err == nil {
+		p.space()
+		if p.pos < len(p.data) {
+			err = p.errorf("unexpected %q after the top level value", p.data[p.pos])
+		}
+	}
+	if err != nil {
+		log.Println("Error while decoding", err)
+		return *dd, err
+	}
+	j.comments = p.comments
+	return toDecodedData(val)
+}
+
+// Comment returns the comment documenting the key name of the object that
+// becomes the struct parent
+func (j *JSON5) Comment(parent, name string) string {
+	return j.comments[parent][name]
+}
+
+// Source of this JSON5 instance, the file name or empty if read from a reader
+func (j *JSON5) Source() string {
+	return j.File
+}
+
+// Annotate a field name with its json tag
+func (j *JSON5) Annotate(name string) string {
+	return "json:" + name
+}
+
+// json5Parser is a recursive descent parser of JSON5, which is a superset of
+// JSONC, into the same values encoding/json decodes JSON into.
+type json5Parser struct {
+	data     []byte
+	pos      int
+	comments keyTexts
+}
+
+func (p *json5Parser) errorf(format string, args ...interface{}) error {
+	line := 1 + bytes.Count(p.data[:p.pos], []byte("\n"))
+	return fmt.Errorf("line %d: %s", line, fmt.Sprintf(format, args...))
+}
+
+func (p *json5Parser) peek() byte {
+	if p.pos < len(p.data) {
+		return p.data[p.pos]
+	}
+	return 0
+}
+
+// space skips white space and comments, returning the text of the comments
+func (p *json5Parser) space() []string {
+	var comments []string
+	for p.pos < len(p.data) {
+		r, size := utf8.DecodeRune(p.data[p.pos:])
+		if unicode.IsSpace(r) || r == '\uFEFF' {
+			p.pos += size
+			continue
+		}
+		c, ok := p.comment()
+		if !ok {
+			break
+		}
+		comments = append(comments, c)
+	}
+	return comments
+}
+
+// lineComment skips blanks and a comma after a value, returning the comment
+// that ends the line of the value and whether the comma was skipped.
+func (p *json5Parser) lineComment() (string, bool) {
+	comma := false
+	for p.pos < len(p.data) {
+		switch p.data[p.pos] {
+		case ' ', '\t':
+			p.pos++
+		case ',':
+			if comma {
+				return "", comma
+			}
+			comma = true
+			p.pos++
+		case '/':
+			start := p.pos
+			c, ok := p.comment()
+			if !ok || bytes.Contains(p.data[start:p.pos], []byte("\n")) || !p.endOfLine() {
+				// Not a comment of the value but of what follows
+				p.pos = start
+				return "", comma
+			}
+			return c, comma
+		default:
+			return "", comma
+		}
+	}
+	return "", comma
+}
+
+// endOfLine checks if only blanks follow the position up to the end of line
+func (p *json5Parser) endOfLine() bool {
+	for _, c := range p.data[p.pos:] {
+		switch c {
+		case ' ', '\t', '\r':
+			continue
+		case '\n':
+			return true
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// comment reads a line or block comment, if there is one at the position
+func (p *json5Parser) comment() (string, bool) {
+	rest := p.data[p.pos:]
+	switch {
+	case bytes.HasPrefix(rest, []byte("//")):
+		end := bytes.IndexByte(rest, '\n')
+		if end < 0 {
+			end = len(rest)
+		}
+		p.pos += end
+		return strings.TrimSpace(string(rest[2:end])), true
+	case bytes.HasPrefix(rest, []byte("/*")):
+		end := bytes.Index(rest[2:], []byte("*/"))
+		if end < 0 {
+			p.pos = len(p.data)
+			return "", false
+		}
+		p.pos += end + 4
+		var lines []string
+		for _, l := range strings.Split(string(rest[2:end+2]), "\n") {
+			l = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(l), "*"))
+			if l != "" {
+				lines = append(lines, l)
+			}
+		}
+		return strings.Join(lines, "\n"), true
+	default:
+		return "", false
+	}
+}
+
+// value parses the value at the position. Objects record the comments of
+// their keys under parent, the name of the struct they become.
+func (p *json5Parser) value(parent string) (interface{}, error) {
+	switch c := p.peek(); {
+	case c == '{':
+		return p.object(parent)
+	case c == '[':
+		return p.array(parent)
+	case c == '"' || c == '\'':
+		return p.str()
+	case c == '-' || c == '+' || c == '.' || (c >= '0' && c <= '9'):
+		return p.number()
+	case c == 0:
+		return nil, p.errorf("unexpected end of input")
+	default:
+		id := p.ident()
+		switch id {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		case "null":
+			return nil, nil
+		case "Infinity", "NaN":
+			p.pos -= len(id)
+			return p.number()
+		}
+		return nil, p.errorf("unexpected %q", c)
+	}
+}
+
+func (p *json5Parser) object(parent string) (interface{}, error) {
+	p.pos++
+	obj := make(map[string]interface{})
+	for {
+		comments := p.space()
+		if p.peek() == '}' {
+			p.pos++
+			return obj, nil
+		}
+		var key string
+		if c := p.peek(); c == '"' || c == '\'' {
+			s, err := p.str()
+			if err != nil {
+				return nil, err
+			}
+			key = s.(string)
+		} else if key = p.ident(); key == "" {
+			return nil, p.errorf("expected an object key but found %q", c)
+		}
+		p.space()
+		if p.peek() != ':' {
+			return nil, p.errorf("expected ':' after key %s", key)
+		}
+		p.pos++
+		p.space()
+		val, err := p.value(key)
+		if err != nil {
+			return nil, err
+		}
+		obj[key] = val
+
+		trailing, comma := p.lineComment()
+		if trailing != "" {
+			comments = append(comments, trailing)
+		}
+		if len(comments) > 0 {
+			p.comments.add(parent, key, strings.Join(comments, "\n"))
+		}
+		if comma {
+			continue
+		}
+		p.space()
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case '}':
+		default:
+			return nil, p.errorf("expected ',' or '}' after value of key %s", key)
+		}
+	}
+}
+
+func (p *json5Parser) array(parent string) (interface{}, error) {
+	p.pos++
+	arr := make([]interface{}, 0)
+	for {
+		p.space()
+		if p.peek() == ']' {
+			p.pos++
+			return arr, nil
+		}
+		val, err := p.value(parent)
+		if err != nil {
+			return nil, err
+		}
+		arr = append(arr, val)
+		p.space()
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case ']':
+		default:
+			return nil, p.errorf("expected ',' or ']' after array element")
+		}
+	}
+}
+
+// ident reads an unquoted identifier, as allowed for the keys of JSON5
+func (p *json5Parser) ident() string {
+	start := p.pos
+	for p.pos < len(p.data) {
+		r, size := utf8.DecodeRune(p.data[p.pos:])
+		if r != '_' && r != '$' && !unicode.IsLetter(r) && (p.pos == start || !unicode.IsDigit(r)) {
+			break
+		}
+		p.pos += size
+	}
+	return string(p.data[start:p.pos])
+}
+
+// str reads a single or double quoted string
+func (p *json5Parser) str() (interface{}, error) {
+	quote := p.data[p.pos]
+	p.pos++
+	var sb strings.Builder
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		p.pos++
+		switch c {
+		case quote:
+			return sb.String(), nil
+		case '\n':
+			return nil, p.errorf("unterminated string")
+		case '\\':
+			if p.pos >= len(p.data) {
+				return nil, p.errorf("unterminated string")
+			}
+			e := p.data[p.pos]
+			p.pos++
+			switch e {
+			case 'b':
+				sb.WriteByte('\b')
+			case 'f':
+				sb.WriteByte('\f')
+			case 'n':
+				sb.WriteByte('\n')
+			case 'r':
+				sb.WriteByte('\r')
+			case 't':
+				sb.WriteByte('\t')
+			case 'v':
+				sb.WriteByte('\v')
+			case '0':
+				sb.WriteByte(0)
+			case '\n':
+				// A line continuation
+			case '\r':
+				if p.peek() == '\n' {
+					p.pos++
+				}
+			case 'u':
+				if p.pos+4 > len(p.data) {
+					return nil, p.errorf("invalid unicode escape")
+				}
+				r, err := strconv.ParseUint(string(p.data[p.pos:p.pos+4]), 16, 32)
+				if err != nil {
+					return nil, p.errorf("invalid unicode escape")
+				}
+				p.pos += 4
+				sb.WriteRune(rune(r))
+			default:
+				sb.WriteByte(e)
+			}
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return nil, p.errorf("unterminated string")
+}
+
+// number reads a JSON5 number. Like encoding/json every number is a float64.
+func (p *json5Parser) number() (interface{}, error) {
+	start := p.pos
+	sign := 1.0
+	if c := p.peek(); c == '+' || c == '-' {
+		if c == '-' {
+			sign = -1
+		}
+		p.pos++
+	}
+	if id := p.ident(); id == "Infinity" {
+		return sign * math.Inf(1), nil
+	} else if id == "NaN" {
+		return math.NaN(), nil
+	} else if id != "" {
+		return nil, p.errorf("invalid number %s", p.data[start:p.pos])
+	}
+
+	numStart := p.pos
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		if (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F') ||
+			c == '.' || c == 'x' || c == 'X' || ((c == '+' || c == '-') &&
+			(p.data[p.pos-1] == 'e' || p.data[p.pos-1] == 'E')) {
+			p.pos++
+			continue
+		}
+		break
+	}
+	num := string(p.data[numStart:p.pos])
+	if strings.HasPrefix(num, "0x") || strings.HasPrefix(num, "0X") {
+		n, err := strconv.ParseUint(num[2:], 16, 64)
+		if err != nil {
+			return nil, p.errorf("invalid number %s", p.data[start:p.pos])
+		}
+		return sign * float64(n), nil
+	}
+	f, err := strconv.ParseFloat(num, 64)
+	if err != nil {
+		return nil, p.errorf("invalid number %s", p.data[start:p.pos])
+	}
+	return sign * f, nil
+}
@@ -0,0 +1,116 @@
+package togo
+
+import (
+	"bytes"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestJSON5_Decode(t *testing.T) {
+	tests := []struct {
+		tc     string
+		data   string
+		exp    map[string]interface{}
+		expErr bool
+	}{
+		{"Plain JSON", `{"a": 1, "b": [true, null], "c": "x"}`,
+			map[string]interface{}{"a": 1.0, "b": []interface{}{true, nil}, "c": "x"}, false},
+		{"Comments", "// head\n{\n/* a */ \"a\": 1, // after a\n\"b\": 2 /* after b */\n}",
+			map[string]interface{}{"a": 1.0, "b": 2.0}, false},
+		{"Trailing Commas", `{"a": [1, 2,], "b": {"c": 3,},}`,
+			map[string]interface{}{"a": []interface{}{1.0, 2.0}, "b": map[string]interface{}{"c": 3.0}}, false},
+		{"Unquoted Keys And Single Quotes", `{a: 'it\'s', $b_2: "x"}`,
+			map[string]interface{}{"a": "it's", "$b_2": "x"}, false},
+		{"JSON5 Numbers", `{hex: 0xFF, lead: .5, trail: 5., plus: +1, exp: 1e3, neg: -0x10}`,
+			map[string]interface{}{"hex": 255.0, "lead": 0.5, "trail": 5.0, "plus": 1.0,
+				"exp": 1000.0, "neg": -16.0}, false},
+		{"Escapes", `{s: "a\tbA\
+c"}`, map[string]interface{}{"s": "a\tbAc"}, false},
+		{"Missing Comma", `{a: 1 b: 2}`, nil, true},
+		{"Unterminated String", `{a: 'x}`, nil, true},
+		{"Trailing Data", `{a: 1} x`, nil, true},
+		{"Invalid Number", `{a: 1f}`, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tc, func(t *testing.T) {
+			dd, err := NewJSON5Bytes([]byte(tt.data)).Decode()
+			if tt.expErr {
+				if err == nil {
+					t.Errorf("TC: %s: Expected error but did not get any error", tt.tc)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("TC: %s: Did not expect error but got %+v", tt.tc, err)
+			}
+			if !reflect.DeepEqual(dd.mapData, tt.exp) {
+				t.Errorf("TC: %s: Expected %+v but got %+v", tt.tc, tt.exp, dd.mapData)
+			}
+		})
+	}
+}
+
+func TestJSON5_Infinity(t *testing.T) {
+	dd, err := NewJSON5Bytes([]byte(`[Infinity, -Infinity, NaN]`)).Decode()
+	if err != nil {
+		t.Fatalf("Did not expect error but got %+v", err)
+	}
+	if !math.IsInf(dd.sliceData[0].(float64), 1) || !math.IsInf(dd.sliceData[1].(float64), -1) ||
+		!math.IsNaN(dd.sliceData[2].(float64)) {
+		t.Errorf("Expected Infinity, -Infinity and NaN but got %+v", dd.sliceData)
+	}
+}
+
+func TestJSON5_Comment(t *testing.T) {
+	j := &JSON5{File: "samples/jsonc/settings.jsonc"}
+	if _, err := j.Decode(); err != nil {
+		t.Fatalf("Decode failed: %+v", err)
+	}
+	tests := []struct {
+		tc      string
+		parent  string
+		name    string
+		comment string
+	}{
+		{"Leading Comment", rootName, "editor.tabSize", "Size of a tab in spaces"},
+		{"Trailing Comment", rootName, "editor.formatOnSave", "Format every file on save"},
+		{"Block Comment", "files.exclude", "**/dist", "Build output\nis never edited"},
+		{"Inline Block Comment", "languages", "formatter", "Formatter of the language"},
+		{"No Comment", rootName, "languages", ""},
+		{"Comment Before Document", rootName, "files.exclude", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tc, func(t *testing.T) {
+			if got := j.Comment(tt.parent, tt.name); got != tt.comment {
+				t.Errorf("TC: %s: Expected %q but got %q", tt.tc, tt.comment, got)
+			}
+		})
+	}
+}
+
+func TestParse_JSON5(t *testing.T) {
+	dec, err := NewFileDecoder("", "samples/jsonc/settings.jsonc")
+	if err != nil {
+		t.Fatalf("NewFileDecoder failed: %+v", err)
+	}
+	if err = Parse(dec); err != nil {
+		t.Fatalf("Parse failed: %+v", err)
+	}
+	var buf bytes.Buffer
+	if err = WriteStructs(&buf); err != nil {
+		t.Fatalf("WriteStructs failed: %+v", err)
+	}
+	out := buf.String()
+	for _, exp := range []string{
+		"\t// Size of a tab in spaces\n\tEditorTabSize float64 `json:\"editor.tabSize\"`",
+		"\t// Build output\n\t// is never edited\n\tDist bool `json:\"**/dist\"`",
+		"\t// Formatter of the language\n\tFormatter string `json:\"formatter\"`",
+		"\tMaxLine float64 `json:\"maxLine\"`",
+	} {
+		if !strings.Contains(out, exp) {
+			t.Errorf("Expected %q in the structs but got:\n%s", exp, out)
+		}
+	}
+}
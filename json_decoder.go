@@ -1,27 +1,45 @@
 package togo
 
 import (
+	"bufio"
+	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"log"
-	"os"
 	"reflect"
+
+	"gopkg.in/yaml.v3"
 )
 
 // JSON type structure to convert to go struct
 type JSON struct {
-	File string
+	File   string
+	reader io.Reader
+}
+
+// NewJSONReader creates a JSON decoder reading from r instead of a file.
+// The reader is consumed by the first Decode.
+func NewJSONReader(r io.Reader) *JSON {
+	return &JSON{reader: r}
+}
+
+// NewJSONBytes creates a JSON decoder for the in-memory data
+func NewJSONBytes(b []byte) *JSON {
+	return NewJSONReader(bytes.NewReader(b))
 }
 
 // Decode this Json instance into decodedData
 func (j *JSON) Decode() (DecodedData, error) {
 
 	dd := new(DecodedData)
-	f, err := os.Open(j.File)
+	f, err := open(j.File, j.reader)
 	if err != nil {
 		log.Println("Error while reading file", err)
 		return *dd, err
 	}
+	defer f.Close()
 	var val interface{}
 	dec := json.NewDecoder(f)
 	err = dec.Decode(&val)
@@ -29,6 +47,29 @@ func (j *JSON) Decode() (DecodedData, error) {
 		log.Println("Error while decoding", err)
 		return *dd, err
 	}
+	return toDecodedData(val)
+}
+
+// Stream the tokens of this Json instance to fn. Every top level value of
+// the file is streamed, not just the first one.
+func (j *JSON) Stream(fn func(TokenStream) error) error {
+	return streamSource(j.File, j.reader, fn)
+}
+
+// streamSource opens the reader or file and hands its JSON tokens to fn
+func streamSource(file string, r io.Reader, fn func(TokenStream) error) error {
+	f, err := open(file, r)
+	if err != nil {
+		log.Println("Error while reading file", err)
+		return err
+	}
+	defer f.Close()
+	return fn(json.NewDecoder(bufio.NewReader(f)))
+}
+
+// toDecodedData wraps a decoded map or slice into DecodedData
+func toDecodedData(val interface{}) (DecodedData, error) {
+	dd := new(DecodedData)
 	tp := reflect.ValueOf(val)
 	switch tp.Kind() {
 	case reflect.Map:
@@ -44,7 +85,260 @@ func (j *JSON) Decode() (DecodedData, error) {
 	return *dd, nil
 }
 
-// Annotate a string with
-func (j *JSON) Annotate(string) string {
-	return ""
+// Source of this JSON instance, the file name or empty if read from a reader
+func (j *JSON) Source() string {
+	return j.File
+}
+
+// Annotate a field name with its json tag
+func (j *JSON) Annotate(name string) string {
+	return "json:" + name
+}
+
+// NDJSON type structure to convert newline delimited JSON (JSON Lines) to go
+// struct. Every line is a record of its own and all of them are merged into
+// one root type, so fields present only in some records are still captured.
+type NDJSON struct {
+	File   string
+	reader io.Reader
+}
+
+// NewNDJSONReader creates an NDJSON decoder reading from r instead of a file.
+// The reader is consumed by the first Decode.
+func NewNDJSONReader(r io.Reader) *NDJSON {
+	return &NDJSON{reader: r}
+}
+
+// NewNDJSONBytes creates an NDJSON decoder for the in-memory data
+func NewNDJSONBytes(b []byte) *NDJSON {
+	return NewNDJSONReader(bytes.NewReader(b))
+}
+
+// Decode this NDJSON instance into decodedData
+func (n *NDJSON) Decode() (DecodedData, error) {
+
+	dd := new(DecodedData)
+	f, err := open(n.File, n.reader)
+	if err != nil {
+		log.Println("Error while reading file", err)
+		return *dd, err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	for line := 1; ; line++ {
+		b, err := r.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			log.Println("Error while reading line", line, err)
+			return *dd, err
+		}
+		if len(bytes.TrimSpace(b)) > 0 {
+			var val interface{}
+			if derr := json.Unmarshal(b, &val); derr != nil {
+				log.Println("Error while decoding line", line, derr)
+				return *dd, fmt.Errorf("line %d: %v", line, derr)
+			}
+			doc, derr := toDecodedData(val)
+			if derr != nil {
+				return *dd, fmt.Errorf("line %d: %v", line, derr)
+			}
+			dd.documents = append(dd.documents, doc)
+		}
+		if err == io.EOF {
+			break
+		}
+	}
+	if len(dd.documents) == 0 {
+		return *dd, errors.New("No JSON record to decode")
+	}
+	return *dd, nil
+}
+
+// Stream the tokens of every record of this NDJSON instance to fn, one line
+// at a time so that a record must fit on its line as it does for Decode
+func (n *NDJSON) Stream(fn func(TokenStream) error) error {
+	f, err := open(n.File, n.reader)
+	if err != nil {
+		log.Println("Error while reading file", err)
+		return err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	records := 0
+	for line := 1; ; line++ {
+		b, err := r.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			log.Println("Error while reading line", line, err)
+			return err
+		}
+		if len(bytes.TrimSpace(b)) > 0 {
+			rs := &recordStream{Decoder: json.NewDecoder(bytes.NewReader(b))}
+			if serr := fn(rs); serr != nil {
+				log.Println("Error while streaming line", line, serr)
+				return fmt.Errorf("line %d: %v", line, serr)
+			}
+			if _, serr := rs.Decoder.Token(); serr != io.EOF {
+				return fmt.Errorf("line %d: expected a single JSON record", line)
+			}
+			records++
+		}
+		if err == io.EOF {
+			break
+		}
+	}
+	if records == 0 {
+		return errors.New("No JSON record to decode")
+	}
+	return nil
+}
+
+// recordStream is the TokenStream of a single JSON value, e.g. the record of
+// an NDJSON line. More is false once the value is read, leaving anything
+// after it unread, and a value cut short fails with io.ErrUnexpectedEOF.
+type recordStream struct {
+	*json.Decoder
+	depth int
+	read  bool
+}
+
+// Token returns the next token of the value
+func (rs *recordStream) Token() (json.Token, error) {
+	tok, err := rs.Decoder.Token()
+	if err == io.EOF {
+		return nil, io.ErrUnexpectedEOF
+	}
+	switch tok {
+	case json.Delim('{'), json.Delim('['):
+		rs.depth++
+	case json.Delim('}'), json.Delim(']'):
+		rs.depth--
+	}
+	if err == nil && rs.depth == 0 {
+		rs.read = true
+	}
+	return tok, err
+}
+
+// More checks if the value, or the array or object being read, has more
+// tokens
+func (rs *recordStream) More() bool {
+	if rs.depth == 0 {
+		return !rs.read
+	}
+	return rs.Decoder.More()
+}
+
+// MixedTypes is true as the records of an NDJSON file, e.g. events, need not
+// agree on the type of a field
+func (n *NDJSON) MixedTypes() bool {
+	return true
+}
+
+// Source of this NDJSON instance, the file name or empty if read from a reader
+func (n *NDJSON) Source() string {
+	return n.File
+}
+
+// Annotate a field name with its json tag
+func (n *NDJSON) Annotate(name string) string {
+	return "json:" + name
+}
+
+// YAML type structure to convert to go struct. A YAML file can hold a stream
+// of documents separated by "---", every one of which is treated as a sample
+// of the same root type.
+type YAML struct {
+	File   string
+	reader io.Reader
+}
+
+// NewYAMLReader creates a YAML decoder reading from r instead of a file.
+// The reader is consumed by the first Decode.
+func NewYAMLReader(r io.Reader) *YAML {
+	return &YAML{reader: r}
+}
+
+// NewYAMLBytes creates a YAML decoder for the in-memory data
+func NewYAMLBytes(b []byte) *YAML {
+	return NewYAMLReader(bytes.NewReader(b))
+}
+
+// Decode this YAML instance into decodedData
+func (y *YAML) Decode() (DecodedData, error) {
+
+	dd := new(DecodedData)
+	f, err := open(y.File, y.reader)
+	if err != nil {
+		log.Println("Error while reading file", err)
+		return *dd, err
+	}
+	defer f.Close()
+
+	dec := yaml.NewDecoder(f)
+	for {
+		var val interface{}
+		err = dec.Decode(&val)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Println("Error while decoding", err)
+			return *dd, err
+		}
+		if val == nil {
+			log.Println("Skipping empty YAML document")
+			continue
+		}
+		doc, err := toDecodedData(normalizeYAML(val))
+		if err != nil {
+			return *dd, err
+		}
+		dd.documents = append(dd.documents, doc)
+	}
+	if len(dd.documents) == 0 {
+		return *dd, errors.New("No YAML document to decode")
+	}
+	if len(dd.documents) == 1 {
+		return dd.documents[0], nil
+	}
+	return *dd, nil
+}
+
+// Source of this YAML instance, the file name or empty if read from a reader
+func (y *YAML) Source() string {
+	return y.File
+}
+
+// Annotate a field name with its yaml tag
+func (y *YAML) Annotate(name string) string {
+	return "yaml:" + name
+}
+
+// normalizeYAML converts the map[interface{}]interface{} that yaml produces
+// for mappings with non-string keys into map[string]interface{}, so that the
+// decoded data looks the same as the one coming out of encoding/json.
+// Aliases are already resolved by the yaml decoder, so the shared nodes are
+// simply visited once per reference.
+func normalizeYAML(val interface{}) interface{} {
+	switch v := val.(type) {
+	case map[interface{}]interface{}:
+		mp := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			mp[fmt.Sprint(k)] = normalizeYAML(e)
+		}
+		return mp
+	case map[string]interface{}:
+		for k, e := range v {
+			v[k] = normalizeYAML(e)
+		}
+		return v
+	case []interface{}:
+		for i, e := range v {
+			v[i] = normalizeYAML(e)
+		}
+		return v
+	default:
+		return v
+	}
 }
@@ -0,0 +1,251 @@
+package togo
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestYAML_Decode(t *testing.T) {
+	tests := []struct {
+		tc     string
+		file   string
+		verify func(dd DecodedData) bool
+		expErr bool
+	}{
+		{
+			tc:   "Multi Document",
+			file: "samples/yaml/manifests.yaml",
+			verify: func(dd DecodedData) bool {
+				if len(dd.documents) != 2 {
+					return false
+				}
+				kind := dd.documents[1].mapData["kind"]
+				return kind == "Deployment"
+			},
+			expErr: false,
+		},
+		{
+			tc:   "Alias Resolved",
+			file: "samples/yaml/manifests.yaml",
+			verify: func(dd DecodedData) bool {
+				spec := dd.documents[1].mapData["spec"].(map[string]interface{})
+				sel := spec["selector"].(map[string]interface{})
+				labels := sel["matchLabels"].(map[string]interface{})
+				return labels["app"] == "web"
+			},
+			expErr: false,
+		},
+		{
+			tc:   "Missing File",
+			file: "samples/yaml/missing.yaml",
+			verify: func(dd DecodedData) bool {
+				return false
+			},
+			expErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tc, func(t *testing.T) {
+			y := &YAML{File: tt.file}
+			dd, err := y.Decode()
+			if tt.expErr && err == nil {
+				t.Errorf("TC: %s: Expected error but did not get any error", tt.tc)
+			} else if !tt.expErr && err != nil {
+				t.Errorf("TC: %s: Did not expect error but got %#v", tt.tc, err)
+			}
+			if err == nil && !tt.verify(dd) {
+				t.Errorf("TC: %s: Verification failed for decoded data: %+v", tt.tc, dd)
+			}
+		})
+	}
+}
+
+func TestParse_YAML(t *testing.T) {
+	y := &YAML{File: "samples/yaml/manifests.yaml"}
+	if err := Parse(y); err != nil {
+		t.Fatalf("Parse failed: %+v", err)
+	}
+	tests := []struct {
+		tc       string
+		gsName   string
+		field    string
+		dataType FieldDT
+		tag      string
+	}{
+		{"Common Field", "Document", "kind", String, "yaml:kind"},
+		{"Merged Nested Field", "spec", "ports", Slice, "yaml:ports"},
+		{"Field From Second Document", "spec", "replicas", Int, "yaml:replicas"},
+		{"Null Field", "metadata", "creationTimestamp", Interface, "yaml:creationTimestamp"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tc, func(t *testing.T) {
+			gs, ok := NameStructCache[tt.gsName]
+			if !ok {
+				t.Fatalf("TC: %s: GoStruct %s was not generated", tt.tc, tt.gsName)
+			}
+			fld, ok := gs.Fields[tt.field]
+			if !ok {
+				t.Fatalf("TC: %s: Field %s missing in %s", tt.tc, tt.field, tt.gsName)
+			}
+			if fld.dataType != tt.dataType || fld.annotation != tt.tag {
+				t.Errorf("TC: %s: Expected (%v, %s) but got (%v, %s)", tt.tc,
+					tt.dataType, tt.tag, fld.dataType, fld.annotation)
+			}
+		})
+	}
+}
+
+func TestNDJSON_Decode(t *testing.T) {
+	tests := []struct {
+		tc     string
+		file   string
+		docs   int
+		expErr bool
+	}{
+		{"Every Line A Record", "samples/ndjson/events.ndjson", 3, false},
+		{"Broken Line", "samples/ndjson/broken.ndjson", 0, true},
+		{"Missing File", "samples/ndjson/missing.ndjson", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tc, func(t *testing.T) {
+			n := &NDJSON{File: tt.file}
+			dd, err := n.Decode()
+			if tt.expErr && err == nil {
+				t.Errorf("TC: %s: Expected error but did not get any error", tt.tc)
+			} else if !tt.expErr && err != nil {
+				t.Errorf("TC: %s: Did not expect error but got %#v", tt.tc, err)
+			}
+			if err == nil && len(dd.documents) != tt.docs {
+				t.Errorf("TC: %s: Expected %d records but got %d", tt.tc, tt.docs, len(dd.documents))
+			}
+		})
+	}
+}
+
+func TestNDJSON_Stream(t *testing.T) {
+	tests := []struct {
+		tc     string
+		dec    *NDJSON
+		expErr string
+	}{
+		{"Every Line A Record", &NDJSON{File: "samples/ndjson/events.ndjson"}, ""},
+		{"Broken Line", &NDJSON{File: "samples/ndjson/broken.ndjson"}, "line 2: unexpected EOF"},
+		{"Record Spanning Lines", NewNDJSONBytes([]byte("{\"id\": 1}\n{\"id\":\n2}\n")), "line 2: unexpected EOF"},
+		{"Records On One Line", NewNDJSONBytes([]byte("{\"id\": 1} {\"id\": 2}\n")), "line 1: "},
+		{"No Record", NewNDJSONBytes([]byte("\n\n")), "No JSON record"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tc, func(t *testing.T) {
+			err := Parse(tt.dec)
+			if tt.expErr == "" && err != nil {
+				t.Errorf("TC: %s: Did not expect error but got %+v", tt.tc, err)
+			} else if tt.expErr != "" && (err == nil || !strings.Contains(err.Error(), tt.expErr)) {
+				t.Errorf("TC: %s: Expected error %q but got %v", tt.tc, tt.expErr, err)
+			}
+		})
+	}
+}
+
+func TestParse_NDJSON(t *testing.T) {
+	n := &NDJSON{File: "samples/ndjson/events.ndjson"}
+	if err := Parse(n); err != nil {
+		t.Fatalf("Parse failed: %+v", err)
+	}
+	tests := []struct {
+		tc     string
+		gsName string
+		field  string
+	}{
+		{"Field In Every Record", "Document", "id"},
+		{"Field In Second Record", "Document", "duration"},
+		{"Field In Last Record", "Document", "items"},
+		{"Nested Field In One Record", "user", "country"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tc, func(t *testing.T) {
+			gs, ok := NameStructCache[tt.gsName]
+			if !ok {
+				t.Fatalf("TC: %s: GoStruct %s was not generated", tt.tc, tt.gsName)
+			}
+			if _, ok := gs.Fields[tt.field]; !ok {
+				t.Errorf("TC: %s: Field %s missing in %s", tt.tc, tt.field, tt.gsName)
+			}
+		})
+	}
+}
+
+func TestParse_YAMLEmptyInnerSlice(t *testing.T) {
+	tests := []struct {
+		tc    string
+		data  string
+		field string
+		tp    string
+	}{
+		{"Root Slice", "- [1, 2]\n- []\n", "", ""},
+		{"Empty First", "a:\n  - []\n  - [1, 2]\n", "\tA [][]int `yaml:\"a\"`", ""},
+		{"Slice Of Maps", "a:\n  - - x: 1\n  - []\n", "\tA [][]A `yaml:\"a\"`", "\tX int `yaml:\"x\"`"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tc, func(t *testing.T) {
+			if err := Parse(NewYAMLBytes([]byte(tt.data))); err != nil {
+				t.Fatalf("TC: %s: Parse failed: %+v", tt.tc, err)
+			}
+			var buf bytes.Buffer
+			if err := WriteStructs(&buf); err != nil {
+				t.Fatalf("TC: %s: WriteStructs failed: %+v", tt.tc, err)
+			}
+			out := buf.String()
+			for _, exp := range []string{tt.field, tt.tp} {
+				if !strings.Contains(out, exp) {
+					t.Errorf("TC: %s: Expected %q in the structs but got:\n%s", tt.tc, exp, out)
+				}
+			}
+		})
+	}
+}
+
+func TestParse_YAMLFoldedKeys(t *testing.T) {
+	data := "user-id: 1\nuser.id: 2\nuser_id: 3\n"
+	if err := Parse(NewYAMLBytes([]byte(data))); err != nil {
+		t.Fatalf("Parse failed: %+v", err)
+	}
+	var buf bytes.Buffer
+	if err := WriteStructs(&buf); err != nil {
+		t.Fatalf("WriteStructs failed: %+v", err)
+	}
+	out := buf.String()
+	for _, exp := range []string{
+		"\tUserID int `yaml:\"user-id\"`",
+		"\tUserID2 int `yaml:\"user.id\"`",
+		"\tUserID3 int `yaml:\"user_id\"`",
+	} {
+		if !strings.Contains(out, exp) {
+			t.Errorf("Expected %q in the structs but got:\n%s", exp, out)
+		}
+	}
+}
+
+func TestParse_NDJSONMixedTypes(t *testing.T) {
+	data := `{"id": 1, "code": 200, "user": {"zip": 12345}}
+{"id": "e2", "code": 404.5, "user": {"zip": "AB1 2CD"}}
+{"id": 3, "code": null}
+`
+	if err := Parse(NewNDJSONBytes([]byte(data))); err != nil {
+		t.Fatalf("Parse failed: %+v", err)
+	}
+	var buf bytes.Buffer
+	if err := WriteStructs(&buf); err != nil {
+		t.Fatalf("WriteStructs failed: %+v", err)
+	}
+	out := buf.String()
+	for _, exp := range []string{
+		"\tID interface{} `json:\"id\"`",
+		"\tCode float64 `json:\"code\"`",
+		"\tZip interface{} `json:\"zip\"`",
+	} {
+		if !strings.Contains(out, exp) {
+			t.Errorf("Expected %q in the structs but got:\n%s", exp, out)
+		}
+	}
+}
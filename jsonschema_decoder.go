@@ -0,0 +1,640 @@
+package togo
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// JSONSchema type structure to convert a JSON Schema (draft-07 or 2020-12),
+// written in JSON or YAML, to go structs. The structs are built from the
+// schema rather than inferred from samples: every object with properties is
+// a struct, the properties that are not required are tagged omitempty and
+// descriptions and enums become doc comments. The targets of $ref are built
+// once as structs named after the definition, shared by all the references,
+// and the definitions that are not referenced are built as well. The root
+// struct is named after the title of the schema if it has one, and the
+// structs of inline objects after their parent and property, e.g. OrderLines.
+type JSONSchema struct {
+	File   string
+	reader io.Reader
+}
+
+// NewJSONSchemaReader creates a JSONSchema decoder reading from r instead of
+// a file. The reader is consumed by the first Build.
+func NewJSONSchemaReader(r io.Reader) *JSONSchema {
+	return &JSONSchema{reader: r}
+}
+
+// NewJSONSchemaBytes creates a JSONSchema decoder for the in-memory schema
+func NewJSONSchemaBytes(b []byte) *JSONSchema {
+	return NewJSONSchemaReader(bytes.NewReader(b))
+}
+
+// Decode is not supported since a schema describes types and holds no data
+// to infer them from. The parser uses Build instead.
+func (js *JSONSchema) Decode() (DecodedData, error) {
+	return DecodedData{}, errors.New("A JSON Schema has no data to decode, its structs are built")
+}
+
+// Build the structs described by the schema into the caches and return the
+// root struct
+func (js *JSONSchema) Build(tr tracker) (*GoStruct, error) {
+	doc, err := loadSchema(js.File, js.reader)
+	if err != nil {
+		return nil, err
+	}
+	b := newSchemaBuilder(doc, tr.source)
+	name := tr.name
+	if title, ok := doc["title"].(string); ok && len(nameWords(title)) > 0 {
+		name = strings.Join(nameWords(title), "_")
+	}
+	b.root = name
+	b.refs["#"] = name
+	b.building["#"] = true
+
+	root := &Field{name: name, sliceNesting: -1}
+	if err = b.typeOf(root, name, tr.level, doc); err != nil {
+		return nil, err
+	}
+	for _, defs := range []string{"$defs", "definitions"} {
+		if err = b.definitions("#/"+defs, doc[defs], tr.level+1); err != nil {
+			return nil, err
+		}
+	}
+	gs, ok := NameStructCache[root.dtStruct]
+	if root.dataType != Map || !ok {
+		return nil, fmt.Errorf("The root of the schema is a %s and not an object", root.elemType())
+	}
+	return gs, nil
+}
+
+// Source of this JSONSchema instance, the file name or empty if read from a reader
+func (js *JSONSchema) Source() string {
+	return js.File
+}
+
+// Annotate a field name with its json tag
+func (js *JSONSchema) Annotate(name string) string {
+	return "json:" + name
+}
+
+// loadSchema reads a schema document written in JSON or YAML
+func loadSchema(file string, reader io.Reader) (map[string]interface{}, error) {
+	f, err := open(file, reader)
+	if err != nil {
+		log.Println("Error while reading file", err)
+		return nil, err
+	}
+	defer f.Close()
+
+	var val interface{}
+	if err = yaml.NewDecoder(f).Decode(&val); err != nil {
+		log.Println("Error while decoding", err)
+		return nil, err
+	}
+	doc, ok := normalizeYAML(val).(map[string]interface{})
+	if !ok {
+		return nil, errors.New("The schema document is not an object")
+	}
+	return doc, nil
+}
+
+// schemaBuilder builds the structs of the schemas of a document. Structs are
+// named after the definition for the targets of $ref, or else after the
+// struct and property holding them, and cached like the structs inferred
+// from samples.
+type schemaBuilder struct {
+	doc    map[string]interface{}
+	source string
+	// root is the name of the struct of the document itself
+	root string
+	// refs maps the $ref built so far onto the name of their struct
+	refs map[string]string
+	// structs has the go names of the definitions and of the inline structs
+	// built so far, which the other inline structs must not take
+	structs map[string]bool
+	// building has the $ref whose struct is being built, which recursive
+	// schemas refer to by pointer
+	building map[string]bool
+	// openAPI is set for the schemas of an OpenAPI document, whose nullable
+	// properties become pointers
+	openAPI bool
+}
+
+func newSchemaBuilder(doc map[string]interface{}, source string) *schemaBuilder {
+	b := &schemaBuilder{doc: doc, source: source, refs: make(map[string]string),
+		structs: make(map[string]bool), building: make(map[string]bool)}
+	components, _ := doc["components"].(map[string]interface{})
+	for _, defs := range []interface{}{doc["$defs"], doc["definitions"], components["schemas"]} {
+		mp, _ := defs.(map[string]interface{})
+		for n := range mp {
+			b.structs[goName(n)] = true
+		}
+	}
+	return b
+}
+
+// inlineName returns the name of the struct of an inline object schema,
+// which is name unless a definition or another inline struct has the same
+// go name, e.g. an inline address of the Order and the Order_address
+// definition. A number is added to the name then.
+func (b *schemaBuilder) inlineName(name string) string {
+	if name == b.root {
+		return name
+	}
+	un := name
+	for n := 2; b.structs[goName(un)]; n++ {
+		un = fmt.Sprintf("%s%d", name, n)
+	}
+	b.structs[goName(un)] = true
+	return un
+}
+
+// definitions builds the structs of the object schemas of a map of
+// definitions, found at the pointer, which were not referenced
+func (b *schemaBuilder) definitions(pointer string, defs interface{}, level int) error {
+	mp, ok := defs.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	names := make([]string, 0, len(mp))
+	for n := range mp {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		s, _ := mp[n].(map[string]interface{})
+		ref := pointer + "/" + strings.NewReplacer("~", "~0", "/", "~1").Replace(n)
+		if _, ok := b.refs[ref]; ok || !b.isObject(s) {
+			continue
+		}
+		if _, err := b.refStruct(ref, s, level); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolve returns the schema a local $ref, i.e. a JSON pointer into the
+// document like "#/$defs/Address", points to
+func (b *schemaBuilder) resolve(ref string) (map[string]interface{}, error) {
+	if !strings.HasPrefix(ref, "#") {
+		return nil, fmt.Errorf("$ref %s is not local to the document", ref)
+	}
+	var cur interface{} = b.doc
+	for _, tok := range strings.Split(strings.TrimPrefix(ref[1:], "/"), "/") {
+		if tok == "" {
+			continue
+		}
+		tok = strings.NewReplacer("~1", "/", "~0", "~").Replace(tok)
+		switch v := cur.(type) {
+		case map[string]interface{}:
+			cur = v[tok]
+		case []interface{}:
+			idx, err := strconv.Atoi(tok)
+			if err != nil || idx < 0 || idx >= len(v) {
+				return nil, fmt.Errorf("$ref %s points to no schema", ref)
+			}
+			cur = v[idx]
+		default:
+			cur = nil
+		}
+	}
+	s, ok := cur.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("$ref %s points to no schema", ref)
+	}
+	return s, nil
+}
+
+// deref returns the schema a $ref points to, or the schema itself
+func (b *schemaBuilder) deref(s map[string]interface{}) map[string]interface{} {
+	if ref, ok := s["$ref"].(string); ok {
+		if t, err := b.resolve(ref); err == nil {
+			return t
+		}
+	}
+	return s
+}
+
+// refStruct returns the name of the struct of the $ref, building it first
+// if it was not built yet. The name is known before the struct is built so
+// that recursive schemas end.
+func (b *schemaBuilder) refStruct(ref string, s map[string]interface{}, level int) (string, error) {
+	if name, ok := b.refs[ref]; ok {
+		return name, nil
+	}
+	name := ref[strings.LastIndex(ref, "/")+1:]
+	name = strings.NewReplacer("~1", "/", "~0", "~").Replace(name)
+	b.refs[ref] = name
+	b.building[ref] = true
+	defer delete(b.building, ref)
+	if _, err := b.structOf(name, level, s); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// variants returns the schemas of oneOf and anyOf, but for the null ones
+func (b *schemaBuilder) variants(s map[string]interface{}) []map[string]interface{} {
+	var vs []map[string]interface{}
+	for _, key := range []string{"oneOf", "anyOf"} {
+		subs, _ := s[key].([]interface{})
+		for _, sub := range subs {
+			sm, ok := sub.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if t := schemaTypes(b.deref(sm)); len(t) == 1 && t[0] == "null" {
+				continue
+			}
+			vs = append(vs, sm)
+		}
+	}
+	return vs
+}
+
+// isObject checks if a schema describes an object with known properties,
+// which becomes a struct
+func (b *schemaBuilder) isObject(s map[string]interface{}) bool {
+	if s == nil {
+		return false
+	}
+	s = b.deref(s)
+	if _, ok := s["properties"]; ok {
+		return true
+	}
+	if all, ok := s["allOf"].([]interface{}); ok {
+		for _, sub := range all {
+			if sm, ok := sub.(map[string]interface{}); ok && b.isObject(sm) {
+				return true
+			}
+		}
+	}
+	vs := b.variants(s)
+	for _, v := range vs {
+		if !b.isObject(v) {
+			return false
+		}
+	}
+	return len(vs) > 0
+}
+
+// properties collects the properties of an object schema, along with those
+// of the schemas it is made of with allOf, oneOf and anyOf. Only the
+// properties required by the schema, or by all of allOf, are required.
+func (b *schemaBuilder) properties(s map[string]interface{}, props map[string]map[string]interface{},
+	required map[string]bool, optional bool) {
+
+	s = b.deref(s)
+	if mp, ok := s["properties"].(map[string]interface{}); ok {
+		for k, v := range mp {
+			ps, _ := v.(map[string]interface{})
+			if ps == nil {
+				ps = map[string]interface{}{}
+			}
+			if _, ok := props[k]; !ok {
+				props[k] = ps
+			}
+		}
+	}
+	if req, ok := s["required"].([]interface{}); ok && !optional {
+		for _, r := range req {
+			if n, ok := r.(string); ok {
+				required[n] = true
+			}
+		}
+	}
+	if all, ok := s["allOf"].([]interface{}); ok {
+		for _, sub := range all {
+			if sm, ok := sub.(map[string]interface{}); ok {
+				b.properties(sm, props, required, optional)
+			}
+		}
+	}
+	for _, v := range b.variants(s) {
+		b.properties(v, props, required, true)
+	}
+}
+
+// structOf builds the struct of an object schema and caches it
+func (b *schemaBuilder) structOf(name string, level int, s map[string]interface{}) (*GoStruct, error) {
+	props := make(map[string]map[string]interface{})
+	required := make(map[string]bool)
+	b.properties(s, props, required, false)
+
+	gs := &GoStruct{Name: name, Level: level}
+	keys := make([]string, 0, len(props))
+	for k := range props {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		f := &Field{name: k, sliceNesting: -1}
+		if err := b.typeOf(f, name+"_"+k, level+1, props[k]); err != nil {
+			return nil, fmt.Errorf("%s.%s: %v", name, k, err)
+		}
+		if b.openAPI && b.nullable(props[k]) {
+			pointer(f)
+		}
+		tag := "json:" + k
+		if !required[k] || (b.openAPI && (props[k]["readOnly"] == true || props[k]["writeOnly"] == true)) {
+			tag += ",omitempty"
+		}
+		f.Annotate(tag)
+		f.Comment(b.comment(props[k]))
+		f.addSource(b.source)
+		if err := gs.AddField(f); err != nil {
+			return nil, err
+		}
+	}
+	if err := b.discriminator(gs, s); err != nil {
+		return nil, err
+	}
+	if err := Cache(gs); err != nil {
+		return nil, err
+	}
+	return gs, nil
+}
+
+// comment returns the doc comment of a property: its description followed by
+// the values it is restricted to and, for OpenAPI, its access
+func (b *schemaBuilder) comment(s map[string]interface{}) string {
+	desc, _ := s["description"].(string)
+	if desc == "" {
+		desc, _ = b.deref(s)["description"].(string)
+	}
+	lines := []string{}
+	if d := strings.TrimSpace(desc); d != "" {
+		lines = append(lines, d)
+	}
+	if b.openAPI && s["readOnly"] == true {
+		lines = append(lines, "Read only, sent in responses but not in requests")
+	} else if b.openAPI && s["writeOnly"] == true {
+		lines = append(lines, "Write only, sent in requests but not in responses")
+	}
+	if enum, ok := b.deref(s)["enum"].([]interface{}); ok && len(enum) > 0 {
+		vals := make([]string, len(enum))
+		for i, e := range enum {
+			vals[i] = fmt.Sprint(e)
+		}
+		lines = append(lines, "One of: "+strings.Join(vals, ", "))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// nullable checks if a schema allows null, with the nullable keyword of
+// OpenAPI 3.0, a null type or a null variant
+func (b *schemaBuilder) nullable(s map[string]interface{}) bool {
+	if s["nullable"] == true {
+		return true
+	}
+	for _, t := range schemaTypes(s) {
+		if t == "null" {
+			return true
+		}
+	}
+	for _, key := range []string{"oneOf", "anyOf"} {
+		subs, _ := s[key].([]interface{})
+		for _, sub := range subs {
+			if sm, ok := sub.(map[string]interface{}); ok {
+				if t := schemaTypes(b.deref(sm)); len(t) == 1 && t[0] == "null" {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// pointer makes the field a pointer to its type. Slices and interfaces can
+// be nil already and are left alone.
+func pointer(f *Field) {
+	switch f.dataType {
+	case Map, Named:
+		if !strings.HasPrefix(f.dtStruct, "*") {
+			f.dtStruct = "*" + f.dtStruct
+		}
+	case Bool, Int, Int64, Float64, String:
+		f.dtStruct = "*" + f.dataType.goType()
+		f.dataType = Named
+	}
+}
+
+// discriminator makes the property named by the discriminator of an object
+// schema a required string field of the struct, documented with the values
+// telling the variants apart
+func (b *schemaBuilder) discriminator(gs *GoStruct, s map[string]interface{}) error {
+	disc, ok := b.deref(s)["discriminator"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	prop, _ := disc["propertyName"].(string)
+	if prop == "" {
+		return errors.New("discriminator without propertyName")
+	}
+	var vals []string
+	if mapping, ok := disc["mapping"].(map[string]interface{}); ok {
+		for v, ref := range mapping {
+			r := fmt.Sprint(ref)
+			vals = append(vals, fmt.Sprintf("%s (%s)", v, r[strings.LastIndex(r, "/")+1:]))
+		}
+	} else {
+		for _, v := range b.variants(b.deref(s)) {
+			if ref, ok := v["$ref"].(string); ok {
+				vals = append(vals, ref[strings.LastIndex(ref, "/")+1:])
+			}
+		}
+	}
+	sort.Strings(vals)
+	f := &Field{name: prop, dataType: String, sliceNesting: -1}
+	if ex, ok := gs.Fields[prop]; ok {
+		f.comment = ex.comment
+		delete(gs.Fields, prop)
+	}
+	f.Annotate("json:" + prop)
+	f.addSource(b.source)
+	if len(vals) > 0 {
+		doc := "Discriminator of the variants: " + strings.Join(vals, ", ")
+		if f.comment != "" {
+			doc = f.comment + "\n" + doc
+		}
+		f.comment = doc
+	}
+	return gs.AddField(f)
+}
+
+// typeOf sets the type of the field from its schema. Inline objects are
+// built as structs named name, see inlineName, at the level.
+func (b *schemaBuilder) typeOf(f *Field, name string, level int, s map[string]interface{}) error {
+	if ref, ok := s["$ref"].(string); ok {
+		target, err := b.resolve(ref)
+		if err != nil {
+			return err
+		}
+		if !b.isObject(target) {
+			return b.typeOf(f, name, level, target)
+		}
+		sn, err := b.refStruct(ref, target, level)
+		if err != nil {
+			return err
+		}
+		if b.building[ref] {
+			sn = "*" + sn
+		}
+		f.dataType, f.dtStruct = Map, sn
+		return nil
+	}
+	if b.isObject(s) {
+		// A single schema wrapped in allOf, oneOf or anyOf, e.g. to give a
+		// $ref siblings, is that schema
+		all, _ := s["allOf"].([]interface{})
+		vs := b.variants(s)
+		if s["properties"] == nil && len(all)+len(vs) == 1 {
+			if len(vs) == 1 {
+				return b.typeOf(f, name, level, vs[0])
+			}
+			if sm, ok := all[0].(map[string]interface{}); ok {
+				return b.typeOf(f, name, level, sm)
+			}
+		}
+		sn := b.inlineName(name)
+		if _, err := b.structOf(sn, level, s); err != nil {
+			return err
+		}
+		f.dataType, f.dtStruct = Map, sn
+		return nil
+	}
+	if vs := b.variants(s); len(vs) > 0 {
+		if len(vs) > 1 {
+			f.dataType = Interface
+			return nil
+		}
+		return b.typeOf(f, name, level, vs[0])
+	}
+
+	types := schemaTypes(s)
+	if len(types) == 0 {
+		if _, ok := s["items"]; ok {
+			types = []string{"array"}
+		} else if enum, ok := s["enum"].([]interface{}); ok {
+			types = valueTypes(enum)
+		} else if c, ok := s["const"]; ok {
+			types = valueTypes([]interface{}{c})
+		}
+	}
+	var nonNull []string
+	for _, t := range types {
+		if t != "null" {
+			nonNull = append(nonNull, t)
+		}
+	}
+	sort.Strings(nonNull)
+	if len(nonNull) != 1 {
+		if len(nonNull) == 2 && nonNull[0] == "integer" && nonNull[1] == "number" {
+			f.dataType = Float64
+			return nil
+		}
+		f.dataType = Interface
+		return nil
+	}
+
+	format, _ := s["format"].(string)
+	switch nonNull[0] {
+	case "array":
+		elem := &Field{name: name, sliceNesting: -1}
+		if items, ok := s["items"].(map[string]interface{}); ok {
+			if err := b.typeOf(elem, name, level, items); err != nil {
+				return err
+			}
+		} else {
+			elem.dataType = Interface
+		}
+		f.dataType = Slice
+		if elem.dataType == Slice {
+			f.dtStruct, f.sliceNesting = elem.dtStruct, elem.sliceNesting+1
+		} else {
+			f.dtStruct, f.sliceNesting = elem.elemType(), 1
+		}
+	case "string":
+		switch format {
+		case "date-time":
+			f.dataType, f.dtStruct = Named, "time.Time"
+		case "byte", "binary":
+			f.dataType, f.dtStruct = Named, "[]byte"
+		default:
+			f.dataType = String
+		}
+	case "integer":
+		if format == "int64" {
+			f.dataType = Int64
+		} else {
+			f.dataType = Int
+		}
+	case "number":
+		f.dataType = Float64
+	case "boolean":
+		f.dataType = Bool
+	default:
+		// Objects without properties are free-form
+		f.dataType = Interface
+	}
+	return nil
+}
+
+// schemaTypes returns the types of the type keyword of a schema
+func schemaTypes(s map[string]interface{}) []string {
+	switch t := s["type"].(type) {
+	case string:
+		return []string{t}
+	case []interface{}:
+		ts := make([]string, 0, len(t))
+		for _, e := range t {
+			if n, ok := e.(string); ok {
+				ts = append(ts, n)
+			}
+		}
+		return ts
+	}
+	return nil
+}
+
+// valueTypes returns the schema types of values, e.g. of an enum
+func valueTypes(vals []interface{}) []string {
+	seen := make(map[string]bool)
+	var ts []string
+	for _, v := range vals {
+		t := "null"
+		switch n := v.(type) {
+		case string:
+			t = "string"
+		case bool:
+			t = "boolean"
+		case int, int64:
+			t = "integer"
+		case float64:
+			t = "number"
+			if n == float64(int64(n)) {
+				t = "integer"
+			}
+		case map[string]interface{}:
+			t = "object"
+		case []interface{}:
+			t = "array"
+		}
+		if !seen[t] {
+			seen[t] = true
+			ts = append(ts, t)
+		}
+	}
+	sort.Strings(ts)
+	return ts
+}
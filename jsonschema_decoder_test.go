@@ -0,0 +1,114 @@
+package togo
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestJSONSchema_Build(t *testing.T) {
+	tests := []struct {
+		tc     string
+		schema string
+		exp    []string
+		expErr bool
+	}{
+		{"Scalars", `{"type": "object", "required": ["a"], "properties": {"a": {"type": "integer"},
+			"b": {"type": "boolean"}, "c": {"type": "number"}, "d": {"type": "string", "format": "date-time"}}}`,
+			[]string{"\tA int `json:\"a\"`", "\tB bool `json:\"b,omitempty\"`",
+				"\tC float64 `json:\"c,omitempty\"`", "\tD time.Time `json:\"d,omitempty\"`"}, false},
+		{"Title And Description", `{"title": "user profile", "properties": {"n": {"type": "string",
+			"description": "Name"}}}`, []string{"type UserProfile struct {", "\t// Name\n\tN string"}, false},
+		{"Definitions", `{"properties": {"a": {"$ref": "#/definitions/Item"}, "b": {"type": "array",
+			"items": {"$ref": "#/definitions/Item"}}}, "definitions": {"Item": {"properties": {"x": {"type": "string"}}}}}`,
+			[]string{"\tA Item `json:\"a,omitempty\"`", "\tB []Item `json:\"b,omitempty\"`", "type Item struct {"}, false},
+		{"Nullable And Enum", `{"properties": {"a": {"type": ["string", "null"]}, "b": {"enum": [1, 2]},
+			"c": {"anyOf": [{"type": "null"}, {"type": "number"}]}}}`,
+			[]string{"\tA string", "\t// One of: 1, 2\n\tB int", "\tC float64"}, false},
+		{"Integer Or Number", `{"properties": {"a": {"type": ["integer", "number"]}, "b": {"type": ["number", "integer"]},
+			"c": {"type": ["null", "number", "integer"]}}}`, []string{"\tA float64", "\tB float64", "\tC float64"}, false},
+		{"Recursive", `{"title": "Node", "properties": {"next": {"$ref": "#"}, "children": {"type": "array",
+			"items": {"$ref": "#"}}}}`, []string{"\tNext *Node", "\tChildren []*Node"}, false},
+		{"Inline And Defined", `{"properties": {"address": {"properties": {"a": {"type": "string"}}},
+			"home": {"$ref": "#/$defs/DocumentAddress"}}, "$defs": {"DocumentAddress": {"properties": {"b": {"type": "string"}}}}}`,
+			[]string{"\tAddress DocumentAddress2 `json:\"address,omitempty\"`", "type DocumentAddress2 struct {\n\tA string",
+				"\tHome DocumentAddress `json:\"home,omitempty\"`", "type DocumentAddress struct {\n\tB string"}, false},
+		{"Inline Keys Of Different Parents", `{"title": "Shop", "properties": {"cart": {"properties": {"items": {"type": "array",
+			"items": {"properties": {"sku": {"type": "string"}}}}}}, "order": {"properties": {"items": {"type": "array",
+			"items": {"properties": {"qty": {"type": "integer"}}}}}}}}`,
+			[]string{"\tItems []ShopCartItems `json:\"items,omitempty\"`", "type ShopCartItems struct {\n\tSku string",
+				"\tItems []ShopOrderItems `json:\"items,omitempty\"`", "type ShopOrderItems struct {\n\tQty int"}, false},
+		{"YAML Schema", "type: object\nproperties:\n  id:\n    type: string\n", []string{"\tID string"}, false},
+		{"Root Not Object", `{"type": "string"}`, nil, true},
+		{"Missing Ref", `{"properties": {"a": {"$ref": "#/$defs/Nope"}}}`, nil, true},
+		{"Remote Ref", `{"properties": {"a": {"$ref": "other.json#/x"}}}`, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tc, func(t *testing.T) {
+			LevelOrderCache = make(map[int][]*GoStruct)
+			NameStructCache = make(map[string]*GoStruct)
+			_, err := NewJSONSchemaBytes([]byte(tt.schema)).Build(tracker{name: rootName})
+			if tt.expErr {
+				if err == nil {
+					t.Errorf("TC: %s: Expected error but did not get any error", tt.tc)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("TC: %s: Did not expect error but got %+v", tt.tc, err)
+			}
+			var buf bytes.Buffer
+			if err = WriteStructs(&buf); err != nil {
+				t.Fatalf("TC: %s: WriteStructs failed: %+v", tt.tc, err)
+			}
+			for _, exp := range tt.exp {
+				if !strings.Contains(buf.String(), exp) {
+					t.Errorf("TC: %s: Expected %q in the structs but got:\n%s", tt.tc, exp, buf.String())
+				}
+			}
+		})
+	}
+}
+
+func TestParse_JSONSchema(t *testing.T) {
+	dec, err := NewFileDecoder("", "samples/jsonschema/order.schema.json")
+	if err != nil {
+		t.Fatalf("NewFileDecoder failed: %+v", err)
+	}
+	if err = Parse(dec); err != nil {
+		t.Fatalf("Parse failed: %+v", err)
+	}
+	var buf bytes.Buffer
+	if err = WriteStructs(&buf); err != nil {
+		t.Fatalf("WriteStructs failed: %+v", err)
+	}
+	out := buf.String()
+	for _, exp := range []string{
+		"type Order struct {",
+		"\t// Identifier of the order\n\tID string `json:\"id\"`",
+		"\t// One of: pending, paid, shipped\n\tStatus string `json:\"status\"`",
+		"\tPlacedAt time.Time `json:\"placed_at,omitempty\"`",
+		"\tBillingAddress Address `json:\"billing_address,omitempty\"`",
+		"\tShippingAddress Address `json:\"shipping_address,omitempty\"`",
+		"\tLines []OrderLines `json:\"lines\"`",
+		"\tPayment OrderPayment `json:\"payment,omitempty\"`",
+		"\tQty int `json:\"qty\"`",
+		"\tMatrix [][]float64 `json:\"matrix,omitempty\"`",
+		"\tTotalCents int64 `json:\"total_cents,omitempty\"`",
+		"\tGift bool `json:\"gift,omitempty\"`",
+		"\tCardLast4 string `json:\"card_last4,omitempty\"`",
+		"\tIban string `json:\"iban,omitempty\"`",
+		"\tMetadata interface{} `json:\"metadata,omitempty\"`",
+		"\tNote interface{} `json:\"note,omitempty\"`",
+		"\tReferrer *Customer `json:\"referrer,omitempty\"`",
+		"\t// ISO 3166-1 alpha-2 code\n\t// One of: DE, FR, US\n\tCountry string `json:\"country,omitempty\"`",
+		"type Coupon struct {",
+	} {
+		if !strings.Contains(out, exp) {
+			t.Errorf("Expected %q in the structs but got:\n%s", exp, out)
+		}
+	}
+	if n := strings.Count(out, "type Address struct {"); n != 1 {
+		t.Errorf("Expected the Address struct to be shared but found it %d times:\n%s", n, out)
+	}
+}
@@ -0,0 +1,212 @@
+package togo
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"time"
+	"unicode"
+)
+
+// Logfmt type structure to convert logfmt lines (level=info msg="x" dur=12ms)
+// to go struct. Every line is a record and all the records are merged into
+// one type, the way HandleSlice merges the elements of a slice. The type of a
+// key is inferred from its values on all the lines, so numbers, bools,
+// durations and RFC3339 times are only kept if every line agrees.
+type Logfmt struct {
+	File   string
+	reader io.Reader
+}
+
+// NewLogfmtReader creates a Logfmt decoder reading from r instead of a file.
+// The reader is consumed by the first Decode.
+func NewLogfmtReader(r io.Reader) *Logfmt {
+	return &Logfmt{reader: r}
+}
+
+// NewLogfmtBytes creates a Logfmt decoder for the in-memory data
+func NewLogfmtBytes(b []byte) *Logfmt {
+	return NewLogfmtReader(bytes.NewReader(b))
+}
+
+// logfmtValue is the text of a value and whether it was quoted
+type logfmtValue struct {
+	text   string
+	quoted bool
+}
+
+// logfmtType is the inferred type of the values of a key
+type logfmtType struct {
+	dt       FieldDT
+	duration bool
+	time     bool
+}
+
+// Decode this Logfmt instance into decodedData
+func (l *Logfmt) Decode() (DecodedData, error) {
+
+	dd := new(DecodedData)
+	f, err := open(l.File, l.reader)
+	if err != nil {
+		log.Println("Error while reading file", err)
+		return *dd, err
+	}
+	defer f.Close()
+
+	var records []map[string]logfmtValue
+	types := make(map[string]*logfmtType)
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; sc.Scan(); line++ {
+		rec, err := parseLogfmt(sc.Text())
+		if err != nil {
+			return *dd, fmt.Errorf("line %d: %v", line, err)
+		}
+		if len(rec) == 0 {
+			continue
+		}
+		for k, v := range rec {
+			if types[k] == nil {
+				types[k] = &logfmtType{duration: true, time: true}
+			}
+			types[k].add(v)
+		}
+		records = append(records, rec)
+	}
+	if err = sc.Err(); err != nil {
+		log.Println("Error while reading lines", err)
+		return *dd, err
+	}
+	if len(records) == 0 {
+		return *dd, errors.New("No logfmt record to decode")
+	}
+
+	for _, rec := range records {
+		mp := make(map[string]interface{}, len(rec))
+		for k, v := range rec {
+			mp[k] = types[k].value(v)
+		}
+		dd.sliceData = append(dd.sliceData, mp)
+	}
+	return *dd, nil
+}
+
+// add widens the type to hold the value. Empty values carry no type.
+func (t *logfmtType) add(v logfmtValue) {
+	if v.text == "" {
+		return
+	}
+	if v.quoted {
+		t.dt, t.duration, t.time = String, false, false
+		return
+	}
+	dt := inferScalar(v.text)
+	if dt == String {
+		if _, err := time.ParseDuration(v.text); err != nil {
+			t.duration = false
+		}
+		if _, err := time.Parse(time.RFC3339Nano, v.text); err != nil {
+			t.time = false
+		}
+	} else {
+		t.duration, t.time = false, false
+	}
+	t.dt = widen(t.dt, dt)
+}
+
+// value converts the text of a value into a go value of the type
+func (t *logfmtType) value(v logfmtValue) interface{} {
+	switch {
+	case v.text == "":
+		return nil
+	case t.dt == String && t.duration:
+		d, _ := time.ParseDuration(v.text)
+		return d
+	case t.dt == String && t.time:
+		tm, _ := time.Parse(time.RFC3339Nano, v.text)
+		return tm.UTC()
+	default:
+		return convertScalar(v.text, t.dt)
+	}
+}
+
+// parseLogfmt returns the key/value pairs of a line. A key without a value is
+// a flag and true. Words that are neither pairs nor keys, like the prefix
+// heroku puts before every line, are skipped.
+func parseLogfmt(line string) (map[string]logfmtValue, error) {
+	rec := make(map[string]logfmtValue)
+	for i := 0; i < len(line); {
+		if line[i] == ' ' || line[i] == '\t' {
+			i++
+			continue
+		}
+		start := i
+		for i < len(line) && line[i] > ' ' && line[i] != '=' && line[i] != '"' {
+			i++
+		}
+		key := line[start:i]
+		if i >= len(line) || line[i] != '=' {
+			// A word that is not a pair, skip it whole
+			for i < len(line) && line[i] != ' ' && line[i] != '\t' {
+				i++
+			}
+			if i-start == len(key) && isLogfmtKey(key) {
+				rec[key] = logfmtValue{text: "true"}
+			}
+			continue
+		}
+		i++
+		if key == "" {
+			return nil, fmt.Errorf("missing key before = at column %d", start+1)
+		}
+		if i < len(line) && line[i] == '"' {
+			end := i + 1
+			for ; end < len(line) && line[end] != '"'; end++ {
+				if line[end] == '\\' {
+					end++
+				}
+			}
+			if end >= len(line) {
+				return nil, fmt.Errorf("unterminated value of %s", key)
+			}
+			s, err := strconv.Unquote(line[i : end+1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid value of %s: %v", key, err)
+			}
+			rec[key] = logfmtValue{text: s, quoted: true}
+			i = end + 1
+			continue
+		}
+		start = i
+		for i < len(line) && line[i] != ' ' && line[i] != '\t' {
+			i++
+		}
+		rec[key] = logfmtValue{text: line[start:i]}
+	}
+	return rec, nil
+}
+
+// isLogfmtKey checks if a word looks like a key, i.e. an identifier that may
+// contain dots and dashes
+func isLogfmtKey(w string) bool {
+	for i, r := range w {
+		if !unicode.IsLetter(r) && r != '_' && (i == 0 || (!unicode.IsDigit(r) && r != '.' && r != '-')) {
+			return false
+		}
+	}
+	return w != ""
+}
+
+// Source of this Logfmt instance, the file name or empty if read from a reader
+func (l *Logfmt) Source() string {
+	return l.File
+}
+
+// Annotate a field name with its logfmt tag
+func (l *Logfmt) Annotate(name string) string {
+	return "logfmt:" + name
+}
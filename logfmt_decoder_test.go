@@ -0,0 +1,89 @@
+package togo
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogfmt_Decode(t *testing.T) {
+	tests := []struct {
+		tc     string
+		data   string
+		exp    []interface{}
+		expErr bool
+	}{
+		{"Scalars", "a=1 b=true c=x d=1.5\n", []interface{}{
+			map[string]interface{}{"a": 1, "b": true, "c": "x", "d": 1.5}}, false},
+		{"Quoted Values", `msg="hello \"you\"" n="12"`, []interface{}{
+			map[string]interface{}{"msg": `hello "you"`, "n": "12"}}, false},
+		{"Durations", "dur=12ms\ndur=1m30s\n", []interface{}{
+			map[string]interface{}{"dur": 12 * time.Millisecond},
+			map[string]interface{}{"dur": 90 * time.Second}}, false},
+		{"Times", "ts=2020-08-01T10:00:00Z\n", []interface{}{
+			map[string]interface{}{"ts": time.Date(2020, 8, 1, 10, 0, 0, 0, time.UTC)}}, false},
+		{"Widened Across Lines", "n=1 v=2\nn=2.5 v=x\n", []interface{}{
+			map[string]interface{}{"n": 1.0, "v": "2"},
+			map[string]interface{}{"n": 2.5, "v": "x"}}, false},
+		{"Mixed Duration", "d=5s\nd=soon\n", []interface{}{
+			map[string]interface{}{"d": "5s"},
+			map[string]interface{}{"d": "soon"}}, false},
+		{"Flags And Empty Values", "cached a=\n", []interface{}{
+			map[string]interface{}{"cached": true, "a": nil}}, false},
+		{"Heroku Prefix", "2020-08-01T10:00:00+00:00 app[web.1]: at=info\n\n", []interface{}{
+			map[string]interface{}{"at": "info"}}, false},
+		{"Unterminated Quote", `msg="oops`, nil, true},
+		{"Missing Key", "=x", nil, true},
+		{"No Record", "\n\n", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tc, func(t *testing.T) {
+			dd, err := NewLogfmtBytes([]byte(tt.data)).Decode()
+			if tt.expErr {
+				if err == nil {
+					t.Errorf("TC: %s: Expected error but did not get any error", tt.tc)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("TC: %s: Did not expect error but got %+v", tt.tc, err)
+			}
+			if !reflect.DeepEqual(dd.sliceData, tt.exp) {
+				t.Errorf("TC: %s: Expected %+v but got %+v", tt.tc, tt.exp, dd.sliceData)
+			}
+		})
+	}
+}
+
+func TestParse_Logfmt(t *testing.T) {
+	dec, err := NewFileDecoder("", "samples/logfmt/app.logfmt")
+	if err != nil {
+		t.Fatalf("NewFileDecoder failed: %+v", err)
+	}
+	if err = Parse(dec); err != nil {
+		t.Fatalf("Parse failed: %+v", err)
+	}
+	var buf bytes.Buffer
+	if err = WriteStructs(&buf); err != nil {
+		t.Fatalf("WriteStructs failed: %+v", err)
+	}
+	out := buf.String()
+	for _, exp := range []string{
+		"type Document struct",
+		"\tTime time.Time `logfmt:\"time\"`",
+		"\tLevel string `logfmt:\"level\"`",
+		"\tPort int `logfmt:\"port\"`",
+		"\tTLS bool `logfmt:\"tls\"`",
+		"\tStatus int `logfmt:\"status\"`",
+		"\tDur time.Duration `logfmt:\"dur\"`",
+		"\tRetried bool `logfmt:\"retried\"`",
+		"\tErr string `logfmt:\"err\"`",
+		"\tLatency float64 `logfmt:\"latency\"`",
+	} {
+		if !strings.Contains(out, exp) {
+			t.Errorf("Expected %q in the structs but got:\n%s", exp, out)
+		}
+	}
+}
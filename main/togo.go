@@ -1,26 +1,115 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"log"
+	"net/http"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/daichi-m/togo"
 )
 
+// headers collects the repeated -header flags
+type headers []string
+
+func (h *headers) String() string {
+	return strings.Join(*h, ", ")
+}
+
+func (h *headers) Set(v string) error {
+	if !strings.Contains(v, ":") {
+		return fmt.Errorf("header %q is not of the form Name: value", v)
+	}
+	*h = append(*h, v)
+	return nil
+}
+
 func main() {
 
-	js := new(togo.JSON)
+	format := flag.String("format", "",
+		"format of the input: json, ndjson, jsonc, json5, yaml, toml, xml, csv, tsv, "+
+			"ini, properties, env, logfmt, form, query, har, postman, jsonschema, openapi, avro, proto, "+
+			"msgpack, cbor, bson, extjson or dynamodb. Guessed from the file extension if not set")
+	method := flag.String("method", http.MethodGet, "method of the requests to http(s) URLs")
+	data := flag.String("data", "", "body of the requests to http(s) URLs")
+	token := flag.String("token", "", "bearer token sent with the requests to http(s) URLs")
+	timeout := flag.Duration("timeout", 30*time.Second, "timeout of the requests to http(s) URLs")
+	var hdrs headers
+	flag.Var(&hdrs, "header", "header sent with the requests to http(s) URLs, as \"Name: value\". "+
+		"Can be repeated")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [-format fmt] [file|glob|dir|url ...]\n", os.Args[0])
+		fmt.Fprintln(flag.CommandLine.Output(), "Reads from stdin if no input is given, or where - is given. "+
+			"All the inputs are merged into a single type.")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
 
 	logfile, err := os.Create("../logs/togo.log")
 	if err != nil {
-		fmt.Printf("Error in creating logfile, will log to stdout: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Error in creating logfile, will log to stderr: %v\n", err)
 	} else {
 		log.SetOutput(logfile)
 	}
 	log.SetFlags(log.LstdFlags | log.Lshortfile)
 	wd, _ := os.Getwd()
 	log.Printf("Current working directory: %s \n", wd)
-	js.File = "../samples/json/bake.json"
-	togo.Parse(js)
+
+	var decs []togo.Decoder
+	var files []string
+	inputs := flag.Args()
+	stdin := len(inputs) == 0
+	for _, in := range inputs {
+		if in == "-" {
+			stdin = true
+			continue
+		}
+		if !strings.HasPrefix(in, "http://") && !strings.HasPrefix(in, "https://") {
+			files = append(files, in)
+			continue
+		}
+		h := togo.NewHTTP(in)
+		h.Method = strings.ToUpper(*method)
+		h.Body = []byte(*data)
+		h.Token = *token
+		h.Format = *format
+		h.Timeout = *timeout
+		for _, hdr := range hdrs {
+			kv := strings.SplitN(hdr, ":", 2)
+			h.Header.Add(strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1]))
+		}
+		decs = append(decs, h)
+	}
+	if len(files) > 0 {
+		fdecs, err := togo.NewFileDecoders(*format, files...)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		decs = append(decs, fdecs...)
+	}
+	if stdin {
+		sf := *format
+		if sf == "" {
+			sf = togo.FormatJSON
+		}
+		dec, err := togo.NewReaderDecoder(sf, os.Stdin)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		decs = append(decs, dec)
+	}
+
+	if err = togo.Parse(decs...); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if err = togo.WriteStructs(os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
 }
@@ -0,0 +1,133 @@
+package togo
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"reflect"
+	"time"
+
+	"github.com/vmihailenco/msgpack/v4"
+)
+
+// MsgPack type structure to convert MessagePack to go struct. MessagePack
+// tells signed from unsigned integers and binary from strings, so unsigned
+// integers become uint64 fields and binary data becomes []byte fields. A
+// blob holding several values one after another is decoded as that many
+// samples of the root type.
+type MsgPack struct {
+	File   string
+	reader io.Reader
+}
+
+// NewMsgPackReader creates a MsgPack decoder reading from r instead of a file.
+// The reader is consumed by the first Decode.
+func NewMsgPackReader(r io.Reader) *MsgPack {
+	return &MsgPack{reader: r}
+}
+
+// NewMsgPackBytes creates a MsgPack decoder for the in-memory data
+func NewMsgPackBytes(b []byte) *MsgPack {
+	return NewMsgPackReader(bytes.NewReader(b))
+}
+
+// Decode this MsgPack instance into decodedData
+func (m *MsgPack) Decode() (DecodedData, error) {
+
+	dd := new(DecodedData)
+	f, err := open(m.File, m.reader)
+	if err != nil {
+		log.Println("Error while reading file", err)
+		return *dd, err
+	}
+	defer f.Close()
+
+	dec := msgpack.NewDecoder(f)
+	for {
+		val, err := dec.DecodeInterface()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Println("Error while decoding", err)
+			return *dd, err
+		}
+		doc, err := toDecodedData(normalizeMsgPack(val))
+		if err != nil {
+			return *dd, err
+		}
+		dd.documents = append(dd.documents, doc)
+	}
+	if len(dd.documents) == 0 {
+		return *dd, io.ErrUnexpectedEOF
+	}
+	if len(dd.documents) == 1 {
+		return dd.documents[0], nil
+	}
+	return *dd, nil
+}
+
+// normalizeMsgPack converts the values decoded by msgpack into the types
+// understood by ToField. Signed integers become int if they fit in 32 bits
+// and int64 otherwise, unsigned integers become uint64, float32 becomes
+// float64 and maps with keys other than strings get string keys.
+func normalizeMsgPack(val interface{}) interface{} {
+	switch v := val.(type) {
+	case int8:
+		return int(v)
+	case int16:
+		return int(v)
+	case int32:
+		return int(v)
+	case int64:
+		if v >= math.MinInt32 && v <= math.MaxInt32 {
+			return int(v)
+		}
+		return v
+	case uint8:
+		return uint64(v)
+	case uint16:
+		return uint64(v)
+	case uint32:
+		return uint64(v)
+	case float32:
+		return float64(v)
+	case time.Time:
+		return v.UTC()
+	case *time.Time:
+		return v.UTC()
+	case map[string]interface{}:
+		for k, e := range v {
+			v[k] = normalizeMsgPack(e)
+		}
+		return v
+	case []interface{}:
+		for i, e := range v {
+			v[i] = normalizeMsgPack(e)
+		}
+		return v
+	}
+	// Maps with keys other than strings come decoded into typed maps
+	rv := reflect.ValueOf(val)
+	if rv.Kind() != reflect.Map {
+		return val
+	}
+	mp := make(map[string]interface{}, rv.Len())
+	iter := rv.MapRange()
+	for iter.Next() {
+		mp[fmt.Sprint(iter.Key().Interface())] = normalizeMsgPack(iter.Value().Interface())
+	}
+	return mp
+}
+
+// Source of this MsgPack instance, the file name or empty if read from a reader
+func (m *MsgPack) Source() string {
+	return m.File
+}
+
+// Annotate a field name with its msgpack tag
+func (m *MsgPack) Annotate(name string) string {
+	return "msgpack:" + name
+}
@@ -0,0 +1,95 @@
+package togo
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vmihailenco/msgpack/v4"
+)
+
+func TestMsgPack_Decode(t *testing.T) {
+	b, err := msgpack.Marshal(map[string]interface{}{
+		"small":   int8(-3),
+		"big":     int64(1) << 40,
+		"count":   uint16(7),
+		"ratio":   float32(0.5),
+		"blob":    []byte("raw"),
+		"name":    "togo",
+		"created": time.Date(2020, 8, 1, 0, 0, 0, 0, time.UTC),
+		"nested":  map[interface{}]interface{}{1: "one"},
+	})
+	if err != nil {
+		t.Fatalf("Marshal failed: %+v", err)
+	}
+	dd, err := NewMsgPackBytes(b).Decode()
+	if err != nil {
+		t.Fatalf("Decode failed: %+v", err)
+	}
+	tests := []struct {
+		tc  string
+		key string
+		dt  FieldDT
+		tn  string
+	}{
+		{"Small Int", "small", Int, ""},
+		{"Big Int", "big", Int64, ""},
+		{"Unsigned", "count", Named, "uint64"},
+		{"Float", "ratio", Float64, ""},
+		{"Binary", "blob", Named, "[]byte"},
+		{"String", "name", String, ""},
+		{"Timestamp", "created", Named, "time.Time"},
+		{"Map", "nested", Map, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tc, func(t *testing.T) {
+			f, err := ToField(tt.key, dd.mapData[tt.key])
+			if err != nil {
+				t.Fatalf("TC: %s: ToField failed: %+v", tt.tc, err)
+			}
+			if f.dataType != tt.dt || f.dtStruct != tt.tn {
+				t.Errorf("TC: %s: Expected (%v, %s) but got (%v, %s)", tt.tc,
+					tt.dt.str(), tt.tn, f.dataType.str(), f.dtStruct)
+			}
+		})
+	}
+	if dd.mapData["nested"].(map[string]interface{})["1"] != "one" {
+		t.Errorf("Expected the integer keys to become strings, got %+v", dd.mapData["nested"])
+	}
+}
+
+func TestMsgPack_DecodeInvalid(t *testing.T) {
+	for _, b := range [][]byte{{}, {0xc1}, {0x81, 0xa1}} {
+		if _, err := NewMsgPackBytes(b).Decode(); err == nil {
+			t.Errorf("Expected an error decoding %x", b)
+		}
+	}
+}
+
+func TestParse_MsgPack(t *testing.T) {
+	dec, err := NewFileDecoder("", "samples/msgpack/sessions.msgpack")
+	if err != nil {
+		t.Fatalf("NewFileDecoder failed: %+v", err)
+	}
+	if err = Parse(dec); err != nil {
+		t.Fatalf("Parse failed: %+v", err)
+	}
+	var buf bytes.Buffer
+	if err = WriteStructs(&buf); err != nil {
+		t.Fatalf("WriteStructs failed: %+v", err)
+	}
+	out := buf.String()
+	for _, exp := range []string{
+		"\tUserID uint64 `msgpack:\"user_id\"`",
+		"\tHits int `msgpack:\"hits\"`",
+		"\tToken []byte `msgpack:\"token\"`",
+		"\tCreated time.Time `msgpack:\"created\"`",
+		"\tRoles []string `msgpack:\"roles\"`",
+		"\tMeta Meta `msgpack:\"meta\"`",
+	} {
+		if !strings.Contains(out, exp) {
+			t.Errorf("Expected %q in the structs but got:\n%s", exp, out)
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package togo
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// OpenAPI type structure to convert an OpenAPI 3.0 or 3.1 document, written
+// in JSON or YAML, to go structs. Every object schema of components/schemas
+// becomes a struct named after it, and the inline schemas of the request
+// body and the successful (2xx) response of every operation become structs
+// named after the operationId, e.g. ListUsersResponse, or after the method
+// and path if there is none. The inline objects nested in them are named
+// after their parent too, e.g. ListUsersResponseData for its data property.
+// Schemas are mapped the way JSONSchema maps them, with nullable properties
+// becoming pointers, readOnly and writeOnly properties optional and the
+// property of a discriminator required. The root type holds one field per
+// operation type.
+type OpenAPI struct {
+	File   string
+	reader io.Reader
+}
+
+// NewOpenAPIReader creates an OpenAPI decoder reading from r instead of a
+// file. The reader is consumed by the first Build.
+func NewOpenAPIReader(r io.Reader) *OpenAPI {
+	return &OpenAPI{reader: r}
+}
+
+// NewOpenAPIBytes creates an OpenAPI decoder for the in-memory document
+func NewOpenAPIBytes(b []byte) *OpenAPI {
+	return NewOpenAPIReader(bytes.NewReader(b))
+}
+
+// openAPIMethods are the operations of a path item, in the order they are built
+var openAPIMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// Decode is not supported since an OpenAPI document describes types and
+// holds no data to infer them from. The parser uses Build instead.
+func (o *OpenAPI) Decode() (DecodedData, error) {
+	return DecodedData{}, errors.New("An OpenAPI document has no data to decode, its structs are built")
+}
+
+// Build the structs described by the document into the caches and return
+// the root struct
+func (o *OpenAPI) Build(tr tracker) (*GoStruct, error) {
+	doc, err := loadSchema(o.File, o.reader)
+	if err != nil {
+		return nil, err
+	}
+	if v, _ := doc["openapi"].(string); !strings.HasPrefix(v, "3.") {
+		return nil, fmt.Errorf("Not an OpenAPI 3 document, the openapi version is %q", v)
+	}
+	b := newSchemaBuilder(doc, tr.source)
+	b.openAPI = true
+
+	components, _ := doc["components"].(map[string]interface{})
+	if err = b.definitions("#/components/schemas", components["schemas"], tr.level+1); err != nil {
+		return nil, err
+	}
+
+	root := &GoStruct{Name: tr.name, Level: tr.level}
+	paths, _ := doc["paths"].(map[string]interface{})
+	keys := make([]string, 0, len(paths))
+	for p := range paths {
+		keys = append(keys, p)
+	}
+	sort.Strings(keys)
+	for _, p := range keys {
+		item, _ := paths[p].(map[string]interface{})
+		for _, m := range openAPIMethods {
+			op, ok := item[m].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			name, err := operationName(m, p, op)
+			if err != nil {
+				return nil, err
+			}
+			if err = o.operation(b, root, name, op); err != nil {
+				return nil, fmt.Errorf("%s %s: %v", strings.ToUpper(m), p, err)
+			}
+		}
+	}
+	if len(root.Fields) > 0 {
+		if err = Cache(root); err != nil {
+			return nil, err
+		}
+		return root, nil
+	}
+	// Without operations the components are all there is, the root is the
+	// struct of the first component schema by name
+	schemas, _ := components["schemas"].(map[string]interface{})
+	names := make([]string, 0, len(schemas))
+	for n := range schemas {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		ref := "#/components/schemas/" + strings.NewReplacer("~", "~0", "/", "~1").Replace(n)
+		if name, ok := b.refs[ref]; ok {
+			return NameStructCache[name], nil
+		}
+	}
+	return nil, errors.New("The document has neither operations nor component schemas")
+}
+
+// operation adds the types of the request body and the response of an
+// operation to the root struct
+func (o *OpenAPI) operation(b *schemaBuilder, root *GoStruct, name string, op map[string]interface{}) error {
+	bodies := make(map[string]map[string]interface{})
+	if rb, ok := op["requestBody"].(map[string]interface{}); ok {
+		bodies[name+"_request"] = mediaSchema(b.deref(rb))
+	}
+	if resps, ok := op["responses"].(map[string]interface{}); ok {
+		codes := make([]int, 0, len(resps))
+		for c := range resps {
+			if code, err := strconv.Atoi(c); err == nil && code >= 200 && code < 300 {
+				codes = append(codes, code)
+			}
+		}
+		sort.Ints(codes)
+		if len(codes) > 0 {
+			resp, _ := resps[strconv.Itoa(codes[0])].(map[string]interface{})
+			bodies[name+"_response"] = mediaSchema(b.deref(resp))
+		}
+	}
+	for key, s := range bodies {
+		if s == nil {
+			continue
+		}
+		f := &Field{name: key, sliceNesting: -1}
+		if err := b.typeOf(f, key, root.Level+1, s); err != nil {
+			return err
+		}
+		f.addSource(b.source)
+		if err := root.AddField(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// operationName returns the name of the types of an operation, its
+// operationId or else its method and path
+func operationName(method, path string, op map[string]interface{}) (string, error) {
+	if id, ok := op["operationId"].(string); ok && len(nameWords(id)) > 0 {
+		return strings.Join(nameWords(id), "_"), nil
+	}
+	return routeName(method, path)
+}
+
+// mediaSchema returns the schema of the content of a request body or a
+// response, preferring JSON to the other media types
+func mediaSchema(body map[string]interface{}) map[string]interface{} {
+	content, ok := body["content"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	types := make([]string, 0, len(content))
+	for mt := range content {
+		types = append(types, mt)
+	}
+	sort.Slice(types, func(i, j int) bool {
+		ji, jj := FormatOfContentType(types[i]) == FormatJSON, FormatOfContentType(types[j]) == FormatJSON
+		if ji != jj {
+			return ji
+		}
+		return types[i] < types[j]
+	})
+	for _, mt := range types {
+		media, _ := content[mt].(map[string]interface{})
+		if s, ok := media["schema"].(map[string]interface{}); ok {
+			return s
+		}
+	}
+	return nil
+}
+
+// Source of this OpenAPI instance, the file name or empty if read from a reader
+func (o *OpenAPI) Source() string {
+	return o.File
+}
+
+// Annotate a field name with its json tag
+func (o *OpenAPI) Annotate(name string) string {
+	return "json:" + name
+}
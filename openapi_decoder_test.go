@@ -0,0 +1,124 @@
+package togo
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestOpenAPI_Build(t *testing.T) {
+	tests := []struct {
+		tc     string
+		doc    string
+		exp    []string
+		expErr bool
+	}{
+		{"Components Only", `{"openapi": "3.1.0", "components": {"schemas": {"Tag": {"type": "object",
+			"properties": {"label": {"type": ["string", "null"]}}}}}}`,
+			[]string{"type Tag struct {", "\tLabel *string `json:\"label,omitempty\"`"}, false},
+		{"Operation Names", `{"openapi": "3.0.0", "paths": {"/items/{id}": {"get": {"responses": {"200": {
+			"content": {"application/json": {"schema": {"type": "object", "properties": {"id": {"type": "integer"}}}}}}}},
+			"put": {"operationId": "update-item", "requestBody": {"content": {"application/json": {"schema": {
+			"type": "array", "items": {"type": "string"}}}}}, "responses": {"204": {"description": "done"}}}}}}`,
+			[]string{"\tGetItemsByIDResponse GetItemsByIDResponse\n", "type GetItemsByIDResponse struct {",
+				"\tUpdateItemRequest []string\n"}, false},
+		{"Lowest Success Response", `{"openapi": "3.0.0", "paths": {"/a": {"post": {"operationId": "a", "responses": {
+			"400": {"content": {"application/json": {"schema": {"type": "integer"}}}},
+			"201": {"content": {"application/json": {"schema": {"type": "boolean"}}}},
+			"202": {"content": {"application/json": {"schema": {"type": "string"}}}}}}}}}`,
+			[]string{"\tAResponse bool\n"}, false},
+		{"Shared Inline Property", `{"openapi": "3.0.0", "paths": {"/users": {"get": {"operationId": "listUsers",
+			"responses": {"200": {"content": {"application/json": {"schema": {"type": "object", "properties": {
+			"data": {"type": "array", "items": {"type": "object", "properties": {"id": {"type": "integer"}}}}}}}}}}}},
+			"/orders": {"get": {"operationId": "listOrders", "responses": {"200": {"content": {"application/json": {
+			"schema": {"type": "object", "properties": {"data": {"type": "array", "items": {"type": "object",
+			"properties": {"id": {"type": "string"}}}}}}}}}}}}}}`,
+			[]string{"\tData []ListUsersResponseData `json:\"data,omitempty\"`",
+				"type ListUsersResponseData struct {\n\tID int `json:\"id,omitempty\"`",
+				"\tData []ListOrdersResponseData `json:\"data,omitempty\"`",
+				"type ListOrdersResponseData struct {\n\tID string `json:\"id,omitempty\"`"}, false},
+		{"Swagger 2", `{"swagger": "2.0", "paths": {}}`, nil, true},
+		{"Empty", `{"openapi": "3.0.0", "paths": {}}`, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tc, func(t *testing.T) {
+			LevelOrderCache = make(map[int][]*GoStruct)
+			NameStructCache = make(map[string]*GoStruct)
+			_, err := NewOpenAPIBytes([]byte(tt.doc)).Build(tracker{name: rootName})
+			if tt.expErr {
+				if err == nil {
+					t.Errorf("TC: %s: Expected error but did not get any error", tt.tc)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("TC: %s: Did not expect error but got %+v", tt.tc, err)
+			}
+			var buf bytes.Buffer
+			if err = WriteStructs(&buf); err != nil {
+				t.Fatalf("TC: %s: WriteStructs failed: %+v", tt.tc, err)
+			}
+			for _, exp := range tt.exp {
+				if !strings.Contains(buf.String(), exp) {
+					t.Errorf("TC: %s: Expected %q in the structs but got:\n%s", tt.tc, exp, buf.String())
+				}
+			}
+		})
+	}
+}
+
+func TestOpenAPI_BuildComponentsRoot(t *testing.T) {
+	LevelOrderCache = make(map[int][]*GoStruct)
+	NameStructCache = make(map[string]*GoStruct)
+	// A struct left over from another decoder of the same Parse
+	if err := Cache(&GoStruct{Name: "Account", Level: 1}); err != nil {
+		t.Fatalf("Cache failed: %+v", err)
+	}
+	doc := `{"openapi": "3.0.0", "components": {"schemas": {"Name": {"type": "string"},
+		"Tag": {"type": "object", "properties": {"label": {"type": "string"}}}}}}`
+	root, err := NewOpenAPIBytes([]byte(doc)).Build(tracker{name: rootName})
+	if err != nil {
+		t.Fatalf("Did not expect error but got %+v", err)
+	}
+	if root.Name != "Tag" {
+		t.Errorf("Expected the root Tag but got %s", root.Name)
+	}
+}
+
+func TestParse_OpenAPI(t *testing.T) {
+	dec, err := NewFileDecoder("", "samples/openapi/shop.openapi.yaml")
+	if err != nil {
+		t.Fatalf("NewFileDecoder failed: %+v", err)
+	}
+	if err = Parse(dec); err != nil {
+		t.Fatalf("Parse failed: %+v", err)
+	}
+	var buf bytes.Buffer
+	if err = WriteStructs(&buf); err != nil {
+		t.Fatalf("WriteStructs failed: %+v", err)
+	}
+	out := buf.String()
+	for _, exp := range []string{
+		"\tListUsersResponse ListUsersResponse\n",
+		"\tCreateUserRequest User\n",
+		"\tCreateUserResponse User\n",
+		"\tGetUsersPetsResponse []Pet\n",
+		"\tItems []User `json:\"items\"`",
+		"\tNextPage *int `json:\"next_page,omitempty\"`",
+		"\tNickname *string `json:\"nickname,omitempty\"`",
+		"\tAddress *Address `json:\"address,omitempty\"`",
+		"\t// Read only, sent in responses but not in requests\n\tID int64 `json:\"id,omitempty\"`",
+		"\t// Write only, sent in requests but not in responses\n\tPassword string `json:\"password,omitempty\"`",
+		"\t// Discriminator of the variants: cat (Cat), dog (Dog)\n\tPetType string `json:\"pet_type\"`",
+		"type Cat struct {",
+		"type Dog struct {",
+		"type Error struct {",
+	} {
+		if !strings.Contains(out, exp) {
+			t.Errorf("Expected %q in the structs but got:\n%s", exp, out)
+		}
+	}
+	if n := strings.Count(out, "type Address struct {"); n != 1 {
+		t.Errorf("Expected the Address struct once but found it %d times:\n%s", n, out)
+	}
+}
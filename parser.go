@@ -11,21 +11,64 @@ import (
 // A tracker interface to track the progression tree while converting to
 // go struct from the generic object
 type tracker struct {
-	name    string
-	level   int
-	nesting int
+	name      string
+	level     int
+	nesting   int
+	annotater Annotater
+	commenter Commenter
+	namer     StructNamer
+	source    string
+	mixed     bool
 }
 
 // Clone a give tracker and return a new instance of tracker
 func (t tracker) clone() tracker {
 	tn := tracker{
-		name:    t.name,
-		level:   t.level,
-		nesting: t.nesting,
+		name:      t.name,
+		level:     t.level,
+		nesting:   t.nesting,
+		annotater: t.annotater,
+		commenter: t.commenter,
+		namer:     t.namer,
+		source:    t.source,
+		mixed:     t.mixed,
 	}
 	return tn
 }
 
+// annotate returns the annotation the decoder wants on the field for key,
+// or an empty string if the decoder does not annotate.
+func (t tracker) annotate(key string) string {
+	if t.annotater == nil {
+		return ""
+	}
+	if ka, ok := t.annotater.(KeyAnnotater); ok {
+		return ka.AnnotateKey(t.name, key)
+	}
+	return t.annotater.Annotate(key)
+}
+
+// comment returns the doc comment the decoder has for the key of the
+// struct being tracked, or an empty string if there is none.
+func (t tracker) comment(key string) string {
+	if t.commenter == nil {
+		return ""
+	}
+	return t.commenter.Comment(t.name, key)
+}
+
+// structName returns the name of the struct of the object of key inside the
+// struct being tracked, the key itself unless the decoder names its structs.
+func (t tracker) structName(key string) string {
+	if t.namer == nil {
+		return key
+	}
+	return t.namer.StructName(t.name, key)
+}
+
+// rootName is the name of the root type inferred by Parse
+const rootName = "Document"
+
 var LevelOrderCache map[int][]*GoStruct
 var NameStructCache map[string]*GoStruct
 var trackerCache map[string]*tracker
@@ -42,47 +85,40 @@ func setLogger() error {
 	return nil
 }*/
 
-// Parse this instance of Decoder into a GoStruct.
+// Parse these instances of Decoder into GoStructs. Every Decoder provides
+// samples of the same root type, so all of them are merged into one.
 // Returns an error in case of any error that occurs
-func Parse(dec Decoder) error {
+func Parse(decs ...Decoder) error {
 
 	// if Logger == nil {
 	// 	setLogger()
 	// }
 	// defer Logger.Sync()
-	// LevelOrderCache = make(map[int][]*GoStruct)
-	// NameStructCache = make(map[string]*GoStruct)
-	// trackerCache = make(map[string]*tracker)
+	LevelOrderCache = make(map[int][]*GoStruct)
+	NameStructCache = make(map[string]*GoStruct)
+	trackerCache = make(map[string]*tracker)
 
-	data, err := dec.Decode()
-	if err != nil {
-		log.Fatalf("Error while decoding data: %+v\n", err)
-		return err
-	}
 	var gs *GoStruct
-
-	log.Printf("Decoded data from JSON: %+v\n", data)
+	var nest int
+	var err error
 
 	tr := tracker{
-		name:    "Document",
+		name:    rootName,
 		level:   0,
 		nesting: 0,
 	}
-	var nest int
-
-	if data.mapData != nil {
-		mp := data.mapData
-		gs, err = HandleMap(mp, tr)
+	for _, dec := range decs {
+		gs, nest, err = parseDecoder(dec, tr)
 		if err != nil {
-			log.Fatalf("Error while handling interface: %+v\n", err)
-		}
-	} else if data.sliceData != nil {
-		sl := data.sliceData
-		gs, nest, err = HandleSlice(sl, tr)
-		if err != nil {
-			log.Fatalf("Error while handling interface: %v+\n", err)
+			return err
 		}
 	}
+	if root, ok := NameStructCache[tr.name]; ok {
+		gs = root
+	}
+	if gs == nil {
+		return errors.New("Nothing to parse")
+	}
 	log.Printf("%+v %v %+v \n", gs, nest, err)
 	str := gs.ToStruct()
 	log.Printf(str)
@@ -93,6 +129,90 @@ func Parse(dec Decoder) error {
 	return nil
 }
 
+// parseDecoder parses the data of a single Decoder into the GoStruct of the
+// tracker, growing what was parsed from the other Decoders before.
+func parseDecoder(dec Decoder, tr tracker) (*GoStruct, int, error) {
+	if ann, ok := dec.(Annotater); ok {
+		tr.annotater = ann
+	}
+	if cmt, ok := dec.(Commenter); ok {
+		tr.commenter = cmt
+	}
+	if nm, ok := dec.(StructNamer); ok {
+		tr.namer = nm
+	}
+	if src, ok := dec.(Sourcer); ok {
+		tr.source = src.Source()
+	}
+	if mt, ok := dec.(MixedTyper); ok {
+		tr.mixed = mt.MixedTypes()
+	}
+
+	if sb, ok := dec.(StructBuilder); ok {
+		gs, err := sb.Build(tr)
+		if err != nil {
+			log.Printf("Error while building structs: %+v\n", err)
+			return nil, 0, err
+		}
+		return gs, 0, nil
+	}
+	return parseData(dec, tr)
+}
+
+// parseData infers the structs of the tracker from the data of a Decoder,
+// streamed if the Decoder supports it or else decoded first.
+func parseData(dec Decoder, tr tracker) (*GoStruct, int, error) {
+	var gs *GoStruct
+	var nest int
+
+	if sd, ok := dec.(StreamDecoder); ok {
+		// Streaming decoders never materialise the data, the structs are
+		// inferred straight from the tokens.
+		err := sd.Stream(func(ts TokenStream) error {
+			var serr error
+			gs, nest, serr = StreamValues(ts, tr)
+			return serr
+		})
+		if err != nil {
+			log.Printf("Error while streaming data: %+v\n", err)
+			return nil, 0, err
+		}
+		return gs, nest, nil
+	}
+
+	data, err := dec.Decode()
+	if err != nil {
+		log.Printf("Error while decoding data: %+v\n", err)
+		return nil, 0, err
+	}
+	log.Printf("Decoded data from %s: %+v\n", tr.source, data)
+
+	// A multi-document input is parsed one document at a time. Every document
+	// shares the root name, so Cache grows them all into a single root type.
+	docs := data.documents
+	if len(docs) == 0 {
+		docs = []DecodedData{data}
+	}
+	for _, doc := range docs {
+		if doc.mapData != nil {
+			mp := doc.mapData
+			gs, err = HandleMap(mp, tr)
+			if err != nil {
+				log.Printf("Error while handling interface: %+v\n", err)
+				return nil, 0, err
+			}
+		} else if doc.sliceData != nil {
+			sl := doc.sliceData
+			gs, nest, err = HandleSlice(sl, tr)
+			if err != nil {
+				log.Printf("Error while handling interface: %+v\n", err)
+				return nil, 0, err
+			}
+		}
+	}
+	return gs, nest, nil
+}
+
 // HandleMap takes care of converting a map[string]interface{}
 // into a GoStruct
 func HandleMap(src map[string]interface{}, tr tracker) (*GoStruct, error) {
@@ -102,28 +222,31 @@ func HandleMap(src map[string]interface{}, tr tracker) (*GoStruct, error) {
 	gs := new(GoStruct)
 	gs.Name = tr.name
 	gs.Level = tr.level
+	gs.mixed = tr.mixed
 
 	log.Printf("Iterate and fill up fields on GoStruct %+v\n", gs.Name)
 	for key, val := range src {
 		field, err := ToField(key, val)
 		if err != nil {
-			log.Fatalf("Error while converting to Field: %+v\n", err)
+			log.Printf("Error while converting to Field: %+v\n", err)
+			return nil, err
 		}
+		field.Annotate(tr.annotate(key))
+		field.Comment(tr.comment(key))
+		field.addSource(tr.source)
 		prmtv := field.dataType.primitive()
 		if prmtv == true {
-			log.Printf("Primitive value, setting dtStruct and sliceNesting to defaults\n")
-			field.dtStruct = ""
+			log.Printf("Primitive value, setting sliceNesting to defaults\n")
 			field.sliceNesting = -1
 			gs.AddField(field)
 			log.Printf("Added field: %+v to the gostruct\n", field)
 		} else if field.dataType == Map {
 			log.Printf("Found a map inside a map. Key: %s \n", key)
 			mp := val.(map[string]interface{})
-			ctr := tracker{
-				name:    key,
-				nesting: -1,
-				level:   tr.level + 1,
-			}
+			ctr := tr.clone()
+			ctr.name = tr.structName(key)
+			ctr.nesting = -1
+			ctr.level = tr.level + 1
 			cgs, err := HandleMap(mp, ctr)
 			if err != nil {
 				log.Printf("Failed converting map to GoStruct due to %+v \n", err)
@@ -135,11 +258,9 @@ func HandleMap(src map[string]interface{}, tr tracker) (*GoStruct, error) {
 		} else if field.dataType == Slice {
 			log.Printf("Found a slice inside a map. Key: %+v \n", key)
 			sl := val.([]interface{})
-			ctr := tracker{
-				name:    key,
-				nesting: 1,
-				level:   tr.level,
-			}
+			ctr := tr.clone()
+			ctr.name = tr.structName(key)
+			ctr.nesting = 1
 			cgs, nest, err := HandleSlice(sl, ctr)
 			if err != nil {
 				log.Printf("Failed converting slice to GoStruct due to %+v \n", err)
@@ -164,56 +285,62 @@ func HandleMap(src map[string]interface{}, tr tracker) (*GoStruct, error) {
 }
 
 // HandleSlice takes care of converting a slice of interface{}
-// into an instance of GoStruct
+// into an instance of GoStruct. Slices of primitives produce a GoStruct
+// without fields whose name is the go type of the elements.
 func HandleSlice(src []interface{}, tr tracker) (*GoStruct, int, error) {
 	log.Printf("Tracker for slice: %+v \n", tr)
 	trackerCache[tr.name] = &tr
 
 	name := tr.name
-	var dt0 FieldDT
+	nest := tr.nesting
+	var elem *Field
 	gs := new(GoStruct)
-	var chgs *GoStruct
 
-	for idx, val := range src {
+	for _, val := range src {
 		field, err := ToField(name, val)
 		if err != nil {
 			log.Printf("Error while converting val to field: %+v\n", err)
 			return nil, 0, err
 		}
-		if idx == 0 {
-			dt0 = field.dataType
-		} else if field.dataType != dt0 {
+		if elem == nil {
+			elem = field
+		} else if !elem.merge(field) {
 			log.Printf("Different data-types found inside a list. Expected: %+v, Found: %+v\n",
-				dt0, field.dataType)
+				elem.dataType, field.dataType)
 			return nil, 0, errors.New("Slice not feasible. Found different data-types")
 		}
 
+		var chgs *GoStruct
 		prmtv := field.dataType.primitive()
 		if prmtv {
-			field.dtStruct = ""
-			field.sliceNesting = tr.nesting
+			continue
 		} else if field.dataType == Slice {
-			ctr := tracker{
-				name:    tr.name,
-				level:   tr.level,
-				nesting: tr.nesting + 1,
-			}
+			ctr := tr.clone()
+			ctr.nesting = tr.nesting + 1
 			sl := val.([]interface{})
-			chgs, _, err = HandleSlice(sl, ctr)
+			var cnest int
+			chgs, cnest, err = HandleSlice(sl, ctr)
 			if err != nil {
 				log.Printf("Could not convert the slice to GoStruct: %+v\n", err)
 				return nil, 0, err
 			}
-		} else if field.dataType == Map {
-			ctr := tracker{
-				name:    tr.name,
-				level:   tr.level,
-				nesting: -1,
+			// An empty inner slice tells nothing about the elements and
+			// gives way to the ones seen in the other inner slices.
+			if chgs.Name == anyType && !gs.IsEmpty() {
+				continue
 			}
+			nest = cnest
+		} else if field.dataType == Map {
+			ctr := tr.clone()
+			ctr.nesting = -1
 			mp := val.(map[string]interface{})
 			chgs, err = HandleMap(mp, ctr)
+			if err != nil {
+				log.Printf("Could not convert the map to GoStruct: %+v\n", err)
+				return nil, 0, err
+			}
 		}
-		if gs == nil || gs.IsEmpty() {
+		if gs.IsEmpty() || gs.Name == anyType {
 			gs = chgs
 		} else {
 			err = gs.Grow(chgs)
@@ -223,29 +350,50 @@ func HandleSlice(src []interface{}, tr tracker) (*GoStruct, int, error) {
 			}
 		}
 	}
+	gs = elemStruct(gs, elem)
 	log.Printf("Slice tracker element: %+v produced result %+v with nesting %d \n",
-		tr, gs, tr.nesting)
-	return gs, tr.nesting, nil
+		tr, gs, nest)
+	return gs, nest, nil
 }
 
-// Cache the GoStruct into level order cache and name cache
-func Cache(gs *GoStruct) error {
-	gsl, ok := LevelOrderCache[gs.Level]
-	if !ok {
-		gsl = make([]*GoStruct, 8)
+// elemStruct returns the GoStruct describing the elements of a slice. For
+// slices of primitives, or empty slices, there is no struct built from the
+// elements and a GoStruct named after the go type of the elements is used.
+func elemStruct(gs *GoStruct, elem *Field) *GoStruct {
+	if !gs.IsEmpty() {
+		return gs
 	}
-	gsl = append(gsl, gs)
-	LevelOrderCache[gs.Level] = gsl
+	gs = &GoStruct{Name: anyType}
+	if elem != nil && elem.dataType == Named {
+		gs.Name = elem.dtStruct
+	} else if elem != nil && elem.dataType.primitive() {
+		gs.Name = elem.dataType.goType()
+	}
+	return gs
+}
 
+// Cache the GoStruct into level order cache and name cache
+func Cache(gs *GoStruct) error {
 	gsn, ok := NameStructCache[gs.Name]
 	if !ok {
+		gsl, ok := LevelOrderCache[gs.Level]
+		if !ok {
+			gsl = make([]*GoStruct, 0, 8)
+		}
+		gsl = append(gsl, gs)
+		LevelOrderCache[gs.Level] = gsl
 		NameStructCache[gs.Name] = gs
 		return nil
 	}
-	eq := gs.Equals(gsn)
-	if !eq {
-		log.Printf("Found a GoStruct with same name, but the structs are not equal")
-		return errors.New("Found a different GoStruct with same name")
+	if gsn == gs {
+		return nil
+	}
+	// Structs sharing a name describe the same type seen in different
+	// samples, so the cached one grows to cover both.
+	err := gsn.Grow(gs)
+	if err != nil {
+		log.Printf("Found a GoStruct with same name, but the structs cannot be merged")
+		return err
 	}
 	return nil
 }
@@ -0,0 +1,243 @@
+package togo
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+)
+
+// Postman type structure to convert the saved examples of a Postman v2.1
+// collection to go structs. Every request of the collection, looked up
+// through its folders, gets a type for its request body and one for the
+// response body of its examples, named after the folders and the request:
+// the request "List users" of the folder "Users" gives UsersListUsersResponse,
+// and the objects inside a body after the type and their key. Bodies are
+// decoded by the decoder of their content type and only the examples of
+// successful (2xx) responses are used. The collection variables are replaced
+// in the bodies. The root type holds one field per type.
+type Postman struct {
+	File   string
+	reader io.Reader
+
+	tags keyTexts
+}
+
+// NewPostmanReader creates a Postman decoder reading from r instead of a
+// file. The reader is consumed by the first Decode.
+func NewPostmanReader(r io.Reader) *Postman {
+	return &Postman{reader: r}
+}
+
+// NewPostmanBytes creates a Postman decoder for the in-memory data
+func NewPostmanBytes(b []byte) *Postman {
+	return NewPostmanReader(bytes.NewReader(b))
+}
+
+// postmanItem is a folder, which has items, or a request of a collection
+type postmanItem struct {
+	Name     string            `json:"name"`
+	Item     []postmanItem     `json:"item"`
+	Request  *postmanRequest   `json:"request"`
+	Response []postmanResponse `json:"response"`
+}
+
+type postmanRequest struct {
+	Header []postmanPair `json:"header"`
+	Body   *struct {
+		Mode       string        `json:"mode"`
+		Raw        string        `json:"raw"`
+		URLEncoded []postmanPair `json:"urlencoded"`
+		FormData   []postmanPair `json:"formdata"`
+		Options    struct {
+			Raw struct {
+				Language string `json:"language"`
+			} `json:"raw"`
+		} `json:"options"`
+	} `json:"body"`
+}
+
+type postmanResponse struct {
+	Name            string          `json:"name"`
+	OriginalRequest *postmanRequest `json:"originalRequest"`
+	Code            int             `json:"code"`
+	Header          json.RawMessage `json:"header"`
+	Body            string          `json:"body"`
+	PreviewLanguage string          `json:"_postman_previewlanguage"`
+}
+
+// postmanPair is a header, a variable or a form parameter
+type postmanPair struct {
+	Key      string      `json:"key"`
+	Value    interface{} `json:"value"`
+	Type     string      `json:"type"`
+	Disabled bool        `json:"disabled"`
+}
+
+// postmanLanguages maps the languages of raw bodies onto their content type
+var postmanLanguages = map[string]string{
+	"json": "application/json",
+	"xml":  "application/xml",
+}
+
+// Decode this Postman instance into decodedData
+func (p *Postman) Decode() (DecodedData, error) {
+
+	dd := new(DecodedData)
+	f, err := open(p.File, p.reader)
+	if err != nil {
+		log.Println("Error while reading file", err)
+		return *dd, err
+	}
+	defer f.Close()
+
+	var coll struct {
+		Info *struct {
+			Name string `json:"name"`
+		} `json:"info"`
+		Item     []postmanItem `json:"item"`
+		Variable []postmanPair `json:"variable"`
+	}
+	if err = json.NewDecoder(f).Decode(&coll); err != nil {
+		log.Println("Error while decoding", err)
+		return *dd, err
+	}
+	if coll.Info == nil {
+		return *dd, errors.New("Not a Postman collection, info is missing")
+	}
+
+	vars := make([]string, 0, 2*len(coll.Variable))
+	for _, v := range coll.Variable {
+		vars = append(vars, "{{"+v.Key+"}}", fmt.Sprint(v.Value))
+	}
+	p.tags = make(keyTexts)
+	w := &postmanWalk{tags: p.tags, vars: strings.NewReplacer(vars...)}
+	w.items(coll.Item, nil)
+	switch len(w.documents) {
+	case 0:
+		return *dd, errors.New("No request or example with a body to decode")
+	case 1:
+		return w.documents[0], nil
+	}
+	dd.documents = w.documents
+	return *dd, nil
+}
+
+// postmanWalk walks the items of a collection, collecting a document per
+// request and example that has a body
+type postmanWalk struct {
+	tags      keyTexts
+	vars      *strings.Replacer
+	documents []DecodedData
+}
+
+func (w *postmanWalk) items(items []postmanItem, folders []string) {
+	for _, it := range items {
+		path := append(append([]string{}, folders...), nameWords(it.Name)...)
+		if it.Request == nil {
+			w.items(it.Item, path)
+			continue
+		}
+		name := strings.Join(path, "_")
+		w.add(name, it.Request, nil)
+		for _, ex := range it.Response {
+			w.add(name, ex.OriginalRequest, &ex)
+		}
+	}
+}
+
+// add the document of the request body, and the response body of an example
+func (w *postmanWalk) add(name string, req *postmanRequest, ex *postmanResponse) {
+	doc := make(map[string]interface{})
+	if req != nil && req.Body != nil {
+		b := req.Body
+		switch b.Mode {
+		case "raw":
+			ct := postmanHeader(req.Header, "Content-Type")
+			if ct == "" {
+				ct = postmanLanguages[b.Options.Raw.Language]
+			}
+			if val, ann := w.body(ct, b.Raw); val != nil {
+				addBody(doc, w.tags, name+"_request", val, ann)
+			}
+		case "urlencoded", "formdata":
+			params := b.URLEncoded
+			if b.Mode == "formdata" {
+				params = b.FormData
+			}
+			var pairs []harPair
+			for _, pp := range params {
+				if !pp.Disabled && pp.Type != "file" {
+					pairs = append(pairs, harPair{Name: pp.Key, Value: w.vars.Replace(fmt.Sprint(pp.Value))})
+				}
+			}
+			if len(pairs) > 0 {
+				if m, err := paramsMap(pairs); err == nil {
+					addBody(doc, w.tags, name+"_request", m, &Form{})
+				} else {
+					log.Println("Skipping form body", err)
+				}
+			}
+		}
+	}
+	if ex != nil && (ex.Code == 0 || (ex.Code >= 200 && ex.Code < 300)) {
+		var header []postmanPair
+		_ = json.Unmarshal(ex.Header, &header)
+		ct := postmanHeader(header, "Content-Type")
+		if ct == "" {
+			ct = postmanLanguages[ex.PreviewLanguage]
+		}
+		if val, ann := w.body(ct, ex.Body); val != nil {
+			addBody(doc, w.tags, name+"_response", val, ann)
+		}
+	}
+	if len(doc) > 0 {
+		w.documents = append(w.documents, DecodedData{mapData: doc})
+	}
+}
+
+// body decodes a body, guessing JSON if the content type is not known
+func (w *postmanWalk) body(ct, text string) (interface{}, Annotater) {
+	text = w.vars.Replace(text)
+	if ct == "" {
+		if t := strings.TrimSpace(text); t != "" && (t[0] == '{' || t[0] == '[') {
+			ct = "application/json"
+		}
+	}
+	return decodeBody(ct, text, "")
+}
+
+// postmanHeader returns the value of the enabled header named key
+func postmanHeader(header []postmanPair, key string) string {
+	for _, h := range header {
+		if !h.Disabled && strings.EqualFold(h.Key, key) {
+			return fmt.Sprint(h.Value)
+		}
+	}
+	return ""
+}
+
+// AnnotateKey annotates a field with the tag of the decoder of its body, or
+// no tag for the types of the root
+func (p *Postman) AnnotateKey(parent, name string) string {
+	return p.tags[parent][name]
+}
+
+// StructName names the structs of the objects of the bodies after their
+// request type
+func (p *Postman) StructName(parent, key string) string {
+	return bodyStructName(parent, key)
+}
+
+// Annotate a field name with its json tag
+func (p *Postman) Annotate(name string) string {
+	return "json:" + name
+}
+
+// Source of this Postman instance, the file name or empty if read from a reader
+func (p *Postman) Source() string {
+	return p.File
+}
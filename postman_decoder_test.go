@@ -0,0 +1,118 @@
+package togo
+
+import (
+	"bytes"
+	"encoding/xml"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPostman_Decode(t *testing.T) {
+	coll := func(items string) string {
+		return `{"info": {"name": "c"}, "variable": [{"key": "id", "value": 7}], "item": [` + items + `]}`
+	}
+	tests := []struct {
+		tc     string
+		data   string
+		exp    map[string]interface{}
+		expErr bool
+	}{
+		{"Raw Request Body", coll(`{"name": "Create user", "request": {"body": {"mode": "raw", "raw": "{\"n\": 1}",
+			"options": {"raw": {"language": "json"}}}}}`), map[string]interface{}{
+			"Create_user_request": map[string]interface{}{"n": 1.0}}, false},
+		{"Example Response In Folder", coll(`{"name": "Users", "item": [{"name": "get", "request": {},
+			"response": [{"code": 200, "body": "{\"id\": {{id}}}"}]}]}`), map[string]interface{}{
+			"Users_get_response": map[string]interface{}{"id": 7.0}}, false},
+		{"Form Body", coll(`{"name": "login", "request": {"body": {"mode": "urlencoded", "urlencoded": [
+			{"key": "user", "value": "x"}, {"key": "off", "value": "y", "disabled": true}]}}}`), map[string]interface{}{
+			"login_request": map[string]interface{}{"user": "x"}}, false},
+		{"XML Example", coll(`{"name": "feed", "request": {}, "response": [{"code": 200,
+			"header": [{"key": "Content-Type", "value": "text/xml"}], "body": "<feed><title>x</title></feed>"}]}`),
+			map[string]interface{}{"feed_response": map[string]interface{}{
+				"XMLName": xml.Name{Local: "feed"}, "title": "x"}}, false},
+		{"Error Example Skipped", coll(`{"name": "get", "request": {}, "response": [{"code": 404, "body": "{}"}]}`),
+			nil, true},
+		{"Not A Collection", `{"item": []}`, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tc, func(t *testing.T) {
+			dd, err := NewPostmanBytes([]byte(tt.data)).Decode()
+			if tt.expErr {
+				if err == nil {
+					t.Errorf("TC: %s: Expected error but did not get any error", tt.tc)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("TC: %s: Did not expect error but got %+v", tt.tc, err)
+			}
+			if !reflect.DeepEqual(dd.mapData, tt.exp) {
+				t.Errorf("TC: %s: Expected %+v but got %+v", tt.tc, tt.exp, dd.mapData)
+			}
+		})
+	}
+}
+
+func TestParse_Postman(t *testing.T) {
+	dec, err := NewFileDecoder("", "samples/postman/shop.postman_collection.json")
+	if err != nil {
+		t.Fatalf("NewFileDecoder failed: %+v", err)
+	}
+	if err = Parse(dec); err != nil {
+		t.Fatalf("Parse failed: %+v", err)
+	}
+	var buf bytes.Buffer
+	if err = WriteStructs(&buf); err != nil {
+		t.Fatalf("WriteStructs failed: %+v", err)
+	}
+	out := buf.String()
+	for _, exp := range []string{
+		"\tUsersListUsersResponse []UsersListUsersResponse\n",
+		"\tUsersCreateUserRequest UsersCreateUserRequest\n",
+		"\tUsersCreateUserResponse UsersCreateUserResponse\n",
+		"\tAuthLoginRequest AuthLoginRequest\n",
+		"\tGetOrderResponse GetOrderResponse\n",
+		"\tTags []string `json:\"tags\"`",
+		"\tPhone string `json:\"phone\"`",
+		"\tCreatedAt string `json:\"created_at\"`",
+		"\tRemember bool `form:\"remember\"`",
+		"\tUserID float64 `json:\"user_id\"`",
+		"\tLines []GetOrderResponseLines `json:\"lines\"`",
+	} {
+		if !strings.Contains(out, exp) {
+			t.Errorf("Expected %q in the structs but got:\n%s", exp, out)
+		}
+	}
+	for _, unexp := range []string{"Debug", "Error"} {
+		if strings.Contains(out, unexp) {
+			t.Errorf("Did not expect %q in the structs:\n%s", unexp, out)
+		}
+	}
+}
+
+func TestParse_PostmanSharedKeys(t *testing.T) {
+	coll := `{"info": {"name": "c"}, "item": [
+		{"name": "Users", "item": [{"name": "get", "request": {},
+			"response": [{"code": 200, "body": "{\"result\": {\"id\": 1}}"}]}]},
+		{"name": "Orders", "item": [{"name": "get", "request": {},
+			"response": [{"code": 200, "body": "{\"result\": {\"id\": \"o-1\"}}"}]}]}]}`
+	if err := Parse(NewPostmanBytes([]byte(coll))); err != nil {
+		t.Fatalf("Parse failed: %+v", err)
+	}
+	var buf bytes.Buffer
+	if err := WriteStructs(&buf); err != nil {
+		t.Fatalf("WriteStructs failed: %+v", err)
+	}
+	out := buf.String()
+	for _, exp := range []string{
+		"\tResult UsersGetResponseResult `json:\"result\"`",
+		"\tResult OrdersGetResponseResult `json:\"result\"`",
+		"type UsersGetResponseResult struct {\n\tID float64 `json:\"id\"`",
+		"type OrdersGetResponseResult struct {\n\tID string `json:\"id\"`",
+	} {
+		if !strings.Contains(out, exp) {
+			t.Errorf("Expected %q in the structs but got:\n%s", exp, out)
+		}
+	}
+}
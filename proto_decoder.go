@@ -0,0 +1,737 @@
+package togo
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"strings"
+	"unicode"
+)
+
+// Proto type structure to convert a proto3 .proto file to go structs for the
+// JSON form of its messages, without protoc. Every message, nested ones
+// included, becomes a struct named after it and its enclosing messages, e.g.
+// Order.Line gives OrderLine, with json tags following the proto3 JSON
+// mapping: fields are named in lowerCamelCase or by their json_name option and
+// are omitted when empty, 64-bit integers are encoded as strings, enums are
+// strings documented with their values and maps have string keys. Message
+// fields, optional fields and the fields of a oneof become pointers. The well
+// known types map onto their JSON form, e.g. google.protobuf.Timestamp onto
+// time.Time. The first message of the file is the root struct.
+type Proto struct {
+	File   string
+	reader io.Reader
+}
+
+// NewProtoReader creates a Proto decoder reading from r instead of a file.
+// The reader is consumed by the first Build.
+func NewProtoReader(r io.Reader) *Proto {
+	return &Proto{reader: r}
+}
+
+// NewProtoBytes creates a Proto decoder for the in-memory .proto file
+func NewProtoBytes(b []byte) *Proto {
+	return NewProtoReader(bytes.NewReader(b))
+}
+
+// Decode is not supported since a .proto file describes types and holds no
+// data to infer them from. The parser uses Build instead.
+func (p *Proto) Decode() (DecodedData, error) {
+	return DecodedData{}, errors.New("A .proto file has no data to decode, its structs are built")
+}
+
+// Build the structs of the messages of the file into the caches and return
+// the root struct
+func (p *Proto) Build(tr tracker) (*GoStruct, error) {
+	f, err := open(p.File, p.reader)
+	if err != nil {
+		log.Println("Error while reading file", err)
+		return nil, err
+	}
+	defer f.Close()
+
+	src, err := ioutil.ReadAll(f)
+	if err != nil {
+		log.Println("Error while reading file", err)
+		return nil, err
+	}
+	toks, err := protoTokens(string(src))
+	if err != nil {
+		return nil, err
+	}
+	pp := &protoParser{toks: toks, enums: make(map[string][]string),
+		messages: make(map[string]*protoMessage)}
+	if err = pp.file(); err != nil {
+		return nil, err
+	}
+	if len(pp.order) == 0 {
+		return nil, errors.New("The file has no message")
+	}
+
+	var root *GoStruct
+	for _, m := range pp.order {
+		gs, err := pp.build(m, tr.level+m.depth, tr.source)
+		if err != nil {
+			return nil, err
+		}
+		if root == nil {
+			root = gs
+		}
+	}
+	return root, nil
+}
+
+// Source of this Proto instance, the file name or empty if read from a reader
+func (p *Proto) Source() string {
+	return p.File
+}
+
+// Annotate a field name with its json tag
+func (p *Proto) Annotate(name string) string {
+	return "json:" + name
+}
+
+// protoToken is a word, a symbol or a string literal of a .proto file, with
+// the comment on the lines right above it
+type protoToken struct {
+	text    string
+	str     bool
+	line    int
+	comment string
+}
+
+// protoSymbols are the characters that are tokens on their own
+const protoSymbols = "{}[]<>()=;,:"
+
+// protoTokens splits a .proto file into tokens, dropping the comments that
+// do not document a declaration
+func protoTokens(src string) ([]protoToken, error) {
+	var toks []protoToken
+	var comment []string
+	line, last := 1, 0
+	rs := []rune(src)
+	for i := 0; i < len(rs); i++ {
+		r := rs[i]
+		switch {
+		case r == '\n':
+			line++
+			j := i + 1
+			for j < len(rs) && rs[j] != '\n' && unicode.IsSpace(rs[j]) {
+				j++
+			}
+			if j < len(rs) && rs[j] == '\n' {
+				// A blank line detaches the comment above it
+				comment = nil
+			}
+		case unicode.IsSpace(r):
+		case r == '/' && i+1 < len(rs) && rs[i+1] == '/':
+			j := i
+			for j < len(rs) && rs[j] != '\n' {
+				j++
+			}
+			if line != last {
+				comment = append(comment, strings.TrimSpace(string(rs[i+2:j])))
+			}
+			i = j - 1
+		case r == '/' && i+1 < len(rs) && rs[i+1] == '*':
+			end := strings.Index(string(rs[i+2:]), "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated comment", line)
+			}
+			body := []rune(string(rs[i+2:])[:end])
+			if line != last {
+				for _, l := range strings.Split(string(body), "\n") {
+					if l = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(l), "*")); l != "" {
+						comment = append(comment, l)
+					}
+				}
+			}
+			line += strings.Count(string(body), "\n")
+			i += len(body) + 3
+		case r == '"' || r == '\'':
+			var sb strings.Builder
+			j := i + 1
+			for ; j < len(rs) && rs[j] != r; j++ {
+				if rs[j] == '\n' {
+					return nil, fmt.Errorf("line %d: unterminated string", line)
+				}
+				if rs[j] == '\\' && j+1 < len(rs) {
+					j++
+				}
+				sb.WriteRune(rs[j])
+			}
+			if j == len(rs) {
+				return nil, fmt.Errorf("line %d: unterminated string", line)
+			}
+			toks = append(toks, protoToken{text: sb.String(), str: true, line: line})
+			i, last, comment = j, line, nil
+		case strings.ContainsRune(protoSymbols, r):
+			toks = append(toks, protoToken{text: string(r), line: line})
+			last, comment = line, nil
+		default:
+			j := i
+			for j < len(rs) && !unicode.IsSpace(rs[j]) && !strings.ContainsRune(protoSymbols+"\"'/", rs[j]) {
+				j++
+			}
+			if j == i {
+				return nil, fmt.Errorf("line %d: unexpected %q", line, r)
+			}
+			toks = append(toks, protoToken{text: string(rs[i:j]), line: line,
+				comment: strings.Join(comment, "\n")})
+			i, last, comment = j-1, line, nil
+		}
+	}
+	return toks, nil
+}
+
+// protoMessage is a message declared in a .proto file
+type protoMessage struct {
+	// name is the name of the struct, made of the names of the enclosing
+	// messages and of the message
+	name   string
+	scope  string
+	depth  int
+	fields []protoField
+}
+
+// protoField is a field of a message
+type protoField struct {
+	name     string
+	typ      string
+	key      string
+	repeated bool
+	optional bool
+	oneof    []string
+	jsonName string
+	comment  string
+}
+
+// protoParser parses the declarations of a .proto file
+type protoParser struct {
+	toks []protoToken
+	pos  int
+	pkg  string
+	// messages and enums are keyed by their full name, the package and the
+	// enclosing messages included. The values of an enum are kept to
+	// document its fields.
+	messages map[string]*protoMessage
+	enums    map[string][]string
+	// order is the order of declaration of the messages
+	order []*protoMessage
+}
+
+func (p *protoParser) next() (protoToken, error) {
+	if p.pos == len(p.toks) {
+		return protoToken{}, io.ErrUnexpectedEOF
+	}
+	p.pos++
+	return p.toks[p.pos-1], nil
+}
+
+func (p *protoParser) peek() string {
+	if p.pos == len(p.toks) {
+		return ""
+	}
+	return p.toks[p.pos].text
+}
+
+func (p *protoParser) expect(text string) error {
+	tok, err := p.next()
+	if err != nil {
+		return err
+	}
+	if tok.str || tok.text != text {
+		return fmt.Errorf("line %d: expected %q but got %q", tok.line, text, tok.text)
+	}
+	return nil
+}
+
+// ident reads a name, possibly qualified by dots
+func (p *protoParser) ident() (protoToken, error) {
+	tok, err := p.next()
+	if err != nil {
+		return tok, err
+	}
+	if tok.str || strings.ContainsAny(tok.text, protoSymbols) {
+		return tok, fmt.Errorf("line %d: expected a name but got %q", tok.line, tok.text)
+	}
+	return tok, nil
+}
+
+// skip a statement up to its semicolon, or a block up to its closing brace
+func (p *protoParser) skip() error {
+	depth := 0
+	for {
+		tok, err := p.next()
+		if err != nil {
+			return err
+		}
+		if tok.str {
+			continue
+		}
+		switch tok.text {
+		case "{":
+			depth++
+		case "}":
+			if depth--; depth <= 0 {
+				return nil
+			}
+		case ";":
+			if depth == 0 {
+				return nil
+			}
+		}
+	}
+}
+
+// file parses the top level declarations
+func (p *protoParser) file() error {
+	for p.pos < len(p.toks) {
+		tok, _ := p.next()
+		switch tok.text {
+		case "syntax":
+			if err := p.expect("="); err != nil {
+				return err
+			}
+			v, err := p.next()
+			if err != nil {
+				return err
+			}
+			if v.text != "proto3" {
+				return fmt.Errorf("line %d: only proto3 is supported, the syntax is %q", v.line, v.text)
+			}
+			if err = p.expect(";"); err != nil {
+				return err
+			}
+		case "package":
+			name, err := p.ident()
+			if err != nil {
+				return err
+			}
+			p.pkg = name.text
+			if err = p.expect(";"); err != nil {
+				return err
+			}
+		case "message":
+			if err := p.message(p.pkg, "", 0); err != nil {
+				return err
+			}
+		case "enum":
+			if err := p.enum(p.pkg); err != nil {
+				return err
+			}
+		case ";":
+		default:
+			// import, option, service and extend do not declare types
+			if err := p.skip(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// message parses a message declared in scope, the full name of the package or
+// message around it, and prefix, the struct name of the enclosing message
+func (p *protoParser) message(scope, prefix string, depth int) error {
+	name, err := p.ident()
+	if err != nil {
+		return err
+	}
+	if err = p.expect("{"); err != nil {
+		return err
+	}
+	m := &protoMessage{name: name.text, scope: protoName(scope, name.text), depth: depth}
+	if prefix != "" {
+		m.name = prefix + "_" + name.text
+	}
+	p.messages[m.scope] = m
+	p.order = append(p.order, m)
+
+	for {
+		switch p.peek() {
+		case "}":
+			p.pos++
+			return nil
+		case "message":
+			p.pos++
+			if err = p.message(m.scope, m.name, depth+1); err != nil {
+				return err
+			}
+		case "enum":
+			p.pos++
+			if err = p.enum(m.scope); err != nil {
+				return err
+			}
+		case "oneof":
+			p.pos++
+			if err = p.oneof(m); err != nil {
+				return err
+			}
+		case "option", "reserved", "extensions", "extend":
+			if err = p.skip(); err != nil {
+				return err
+			}
+		case ";":
+			p.pos++
+		case "":
+			return io.ErrUnexpectedEOF
+		default:
+			f, err := p.field()
+			if err != nil {
+				return err
+			}
+			m.fields = append(m.fields, f)
+		}
+	}
+}
+
+// oneof parses the fields of a oneof, which are all optional
+func (p *protoParser) oneof(m *protoMessage) error {
+	if _, err := p.ident(); err != nil {
+		return err
+	}
+	if err := p.expect("{"); err != nil {
+		return err
+	}
+	var fields []protoField
+	for p.peek() != "}" {
+		switch p.peek() {
+		case "option":
+			if err := p.skip(); err != nil {
+				return err
+			}
+		case ";":
+			p.pos++
+		case "":
+			return io.ErrUnexpectedEOF
+		default:
+			f, err := p.field()
+			if err != nil {
+				return err
+			}
+			fields = append(fields, f)
+		}
+	}
+	p.pos++
+	names := make([]string, len(fields))
+	for i, f := range fields {
+		names[i] = f.name
+	}
+	for _, f := range fields {
+		f.oneof = names
+		m.fields = append(m.fields, f)
+	}
+	return nil
+}
+
+// field parses a field, a map field included, up to its semicolon
+func (p *protoParser) field() (protoField, error) {
+	var f protoField
+	tok, err := p.ident()
+	if err != nil {
+		return f, err
+	}
+	f.comment = tok.comment
+	switch tok.text {
+	case "repeated", "optional", "required":
+		f.repeated, f.optional = tok.text == "repeated", tok.text == "optional"
+		if tok, err = p.ident(); err != nil {
+			return f, err
+		}
+	}
+	f.typ = tok.text
+	if f.typ == "map" && p.peek() == "<" {
+		p.pos++
+		key, err := p.ident()
+		if err != nil {
+			return f, err
+		}
+		if err = p.expect(","); err != nil {
+			return f, err
+		}
+		val, err := p.ident()
+		if err != nil {
+			return f, err
+		}
+		if err = p.expect(">"); err != nil {
+			return f, err
+		}
+		f.key, f.typ = key.text, val.text
+	}
+	name, err := p.ident()
+	if err != nil {
+		return f, err
+	}
+	f.name = name.text
+	if err = p.expect("="); err != nil {
+		return f, err
+	}
+	if _, err = p.next(); err != nil {
+		return f, err
+	}
+	if p.peek() == "[" {
+		if f.jsonName, err = p.options(); err != nil {
+			return f, err
+		}
+	}
+	if f.jsonName == "" {
+		f.jsonName = protoJSONName(f.name)
+	}
+	return f, p.expect(";")
+}
+
+// options parses the options of a field and returns its json_name, if set
+func (p *protoParser) options() (string, error) {
+	jsonName := ""
+	depth := 0
+	for {
+		tok, err := p.next()
+		if err != nil {
+			return "", err
+		}
+		if tok.str {
+			continue
+		}
+		switch tok.text {
+		case "[", "{":
+			depth++
+		case "]", "}":
+			if depth--; depth == 0 {
+				return jsonName, nil
+			}
+		case "json_name":
+			if depth == 1 && p.peek() == "=" {
+				p.pos++
+				v, err := p.next()
+				if err != nil {
+					return "", err
+				}
+				jsonName = v.text
+			}
+		}
+	}
+}
+
+// enum parses an enum declared in scope and records its values
+func (p *protoParser) enum(scope string) error {
+	name, err := p.ident()
+	if err != nil {
+		return err
+	}
+	if err = p.expect("{"); err != nil {
+		return err
+	}
+	var values []string
+	for {
+		switch p.peek() {
+		case "}":
+			p.pos++
+			p.enums[protoName(scope, name.text)] = values
+			return nil
+		case "option", "reserved":
+			if err = p.skip(); err != nil {
+				return err
+			}
+		case ";":
+			p.pos++
+		case "":
+			return io.ErrUnexpectedEOF
+		default:
+			v, _ := p.next()
+			values = append(values, v.text)
+			if err = p.skip(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// protoName returns the full name of a name declared in scope
+func protoName(scope, name string) string {
+	if scope == "" {
+		return name
+	}
+	return scope + "." + name
+}
+
+// protoJSONName returns the lowerCamelCase name of a field in JSON, the way
+// protoc derives it: underscores are dropped and the letter after them is
+// upper cased
+func protoJSONName(name string) string {
+	var sb strings.Builder
+	up := false
+	for _, r := range name {
+		if r == '_' {
+			up = true
+			continue
+		}
+		if up {
+			r = unicode.ToUpper(r)
+			up = false
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// protoScalars maps the scalar types of proto3 onto their field
+var protoScalars = map[string]Field{
+	"double":   {dataType: Float64},
+	"float":    {dataType: Float64},
+	"int32":    {dataType: Int},
+	"sint32":   {dataType: Int},
+	"sfixed32": {dataType: Int},
+	"uint32":   {dataType: Int64},
+	"fixed32":  {dataType: Int64},
+	"int64":    {dataType: Int64},
+	"sint64":   {dataType: Int64},
+	"sfixed64": {dataType: Int64},
+	"uint64":   {dataType: Named, dtStruct: "uint64"},
+	"fixed64":  {dataType: Named, dtStruct: "uint64"},
+	"bool":     {dataType: Bool},
+	"string":   {dataType: String},
+	"bytes":    {dataType: Named, dtStruct: "[]byte"},
+}
+
+// protoQuoted has the types whose values are encoded as JSON strings
+var protoQuoted = map[string]bool{
+	"int64": true, "sint64": true, "sfixed64": true, "uint64": true, "fixed64": true,
+	"google.protobuf.Int64Value": true, "google.protobuf.UInt64Value": true,
+}
+
+// protoWellKnown maps the well known types onto the field of their JSON form
+var protoWellKnown = map[string]Field{
+	"google.protobuf.Timestamp":   {dataType: Named, dtStruct: "time.Time"},
+	"google.protobuf.Duration":    {dataType: String, comment: "Duration in seconds, e.g. 1.5s"},
+	"google.protobuf.FieldMask":   {dataType: String, comment: "Comma separated field paths"},
+	"google.protobuf.Struct":      {dataType: Named, dtStruct: "map[string]" + anyType},
+	"google.protobuf.Any":         {dataType: Named, dtStruct: "map[string]" + anyType},
+	"google.protobuf.Empty":       {dataType: Named, dtStruct: "map[string]" + anyType},
+	"google.protobuf.Value":       {dataType: Interface},
+	"google.protobuf.ListValue":   {dataType: Slice, dtStruct: anyType, sliceNesting: 1},
+	"google.protobuf.BoolValue":   {dataType: Named, dtStruct: "*bool"},
+	"google.protobuf.StringValue": {dataType: Named, dtStruct: "*string"},
+	"google.protobuf.BytesValue":  {dataType: Named, dtStruct: "[]byte"},
+	"google.protobuf.Int32Value":  {dataType: Named, dtStruct: "*int"},
+	"google.protobuf.UInt32Value": {dataType: Named, dtStruct: "*int64"},
+	"google.protobuf.Int64Value":  {dataType: Named, dtStruct: "*int64"},
+	"google.protobuf.UInt64Value": {dataType: Named, dtStruct: "*uint64"},
+	"google.protobuf.FloatValue":  {dataType: Named, dtStruct: "*float64"},
+	"google.protobuf.DoubleValue": {dataType: Named, dtStruct: "*float64"},
+}
+
+// resolve returns the full name of the type named in scope: the innermost
+// message or package declaring it, or else the name itself
+func (p *protoParser) resolve(typ, scope string) string {
+	if strings.HasPrefix(typ, ".") {
+		return typ[1:]
+	}
+	for {
+		full := protoName(scope, typ)
+		if _, ok := p.messages[full]; ok {
+			return full
+		}
+		if _, ok := p.enums[full]; ok {
+			return full
+		}
+		if scope == "" {
+			return typ
+		}
+		if i := strings.LastIndex(scope, "."); i >= 0 {
+			scope = scope[:i]
+		} else {
+			scope = ""
+		}
+	}
+}
+
+// typeOf sets the type of the field of a message to the type named typ
+func (p *protoParser) typeOf(f *Field, typ, scope string) {
+	if s, ok := protoScalars[typ]; ok {
+		f.dataType, f.dtStruct = s.dataType, s.dtStruct
+		return
+	}
+	full := p.resolve(typ, scope)
+	if m, ok := p.messages[full]; ok {
+		f.dataType, f.dtStruct = Map, m.name
+		return
+	}
+	if values, ok := p.enums[full]; ok {
+		f.dataType = String
+		f.comment = "One of: " + strings.Join(values, ", ")
+		return
+	}
+	if wk, ok := protoWellKnown[full]; ok {
+		f.dataType, f.dtStruct, f.comment = wk.dataType, wk.dtStruct, wk.comment
+		if wk.dataType == Slice {
+			f.sliceNesting = wk.sliceNesting
+		}
+		return
+	}
+	// Types imported from other files are not known
+	f.dataType = Interface
+	f.comment = fmt.Sprintf("%s is not declared in this file", typ)
+}
+
+// build the struct of a message and cache it
+func (p *protoParser) build(m *protoMessage, level int, source string) (*GoStruct, error) {
+	gs := &GoStruct{Name: m.name, Level: level}
+	for _, pf := range m.fields {
+		f := &Field{name: pf.name, sliceNesting: -1}
+		p.typeOf(f, pf.typ, m.scope)
+		full := p.resolve(pf.typ, m.scope)
+		quoted := protoQuoted[pf.typ] || protoQuoted[full]
+		switch {
+		case pf.key != "":
+			if quoted {
+				f.dataType, f.dtStruct = String, ""
+				f.comment = pf.typ + " encoded as a string"
+			}
+			f.dataType, f.dtStruct, f.sliceNesting = Named, "map[string]"+f.elemType(), -1
+		case pf.repeated:
+			if quoted {
+				// The string option of encoding/json does not apply to slices
+				f.dataType, f.dtStruct = String, ""
+				f.comment = pf.typ + " encoded as a string"
+			}
+			if f.dataType == Slice {
+				f.sliceNesting++
+			} else {
+				f.dtStruct, f.sliceNesting = f.elemType(), 1
+			}
+			f.dataType = Slice
+		case f.dataType == Map, pf.optional, len(pf.oneof) > 0:
+			pointer(f)
+		}
+
+		tag := "json:" + pf.jsonName
+		if quoted && pf.key == "" && !pf.repeated {
+			tag += ",string"
+		}
+		f.Annotate(tag + ",omitempty")
+
+		var lines []string
+		if pf.comment != "" {
+			lines = append(lines, pf.comment)
+		}
+		if f.comment != "" {
+			lines = append(lines, f.comment)
+		}
+		if len(pf.oneof) > 1 {
+			lines = append(lines, "Only one of "+strings.Join(pf.oneof, ", ")+" is set")
+		}
+		f.comment = strings.Join(lines, "\n")
+		f.addSource(source)
+		if err := gs.AddField(f); err != nil {
+			return nil, fmt.Errorf("%s.%s: %v", m.name, pf.name, err)
+		}
+	}
+	if err := Cache(gs); err != nil {
+		return nil, err
+	}
+	return gs, nil
+}
@@ -0,0 +1,118 @@
+package togo
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestProtoJSONName(t *testing.T) {
+	tests := []struct {
+		tc   string
+		name string
+		exp  string
+	}{
+		{"Snake Case", "placed_at", "placedAt"},
+		{"Single Word", "id", "id"},
+		{"Digits", "line_2_total", "line2Total"},
+		{"Camel Case", "alreadyCamel", "alreadyCamel"},
+	}
+	for _, tt := range tests {
+		if got := protoJSONName(tt.name); got != tt.exp {
+			t.Errorf("TC: %s: Expected %s but got %s", tt.tc, tt.exp, got)
+		}
+	}
+}
+
+func TestProto_Build(t *testing.T) {
+	tests := []struct {
+		tc     string
+		proto  string
+		exp    []string
+		expErr bool
+	}{
+		{"Scalars", `syntax = "proto3"; message Point { sint32 x = 1; uint64 big = 2; bool ok = 3; }`,
+			[]string{"type Point struct {", "\tX int `json:\"x,omitempty\"`",
+				"\tBig uint64 `json:\"big,string,omitempty\"`", "\tOk bool `json:\"ok,omitempty\"`"}, false},
+		{"Nested Scopes", `syntax = "proto3"; package a.b;
+			message Outer { message Inner { int32 v = 1; } Inner inner = 1; repeated .a.b.Outer.Inner all = 2;
+			map<int32, Inner> by_id = 3; }`,
+			[]string{"type OuterInner struct {", "\tInner *OuterInner `json:\"inner,omitempty\"`",
+				"\tAll []OuterInner `json:\"all,omitempty\"`",
+				"\tByID map[string]OuterInner `json:\"byId,omitempty\"`"}, false},
+		{"Comments And Imports", `syntax = "proto3";
+			message M {
+				// The id
+				string id = 1; // trailing
+				other.Thing thing = 2;
+			}`,
+			[]string{"\t// The id\n\tID string", "\t// other.Thing is not declared in this file\n\tThing interface{}"}, false},
+		{"Proto2", `syntax = "proto2"; message M { optional int32 a = 1; }`, nil, true},
+		{"No Message", `syntax = "proto3"; enum E { A = 0; }`, nil, true},
+		{"Missing Brace", `syntax = "proto3"; message M { int32 a = 1;`, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tc, func(t *testing.T) {
+			LevelOrderCache = make(map[int][]*GoStruct)
+			NameStructCache = make(map[string]*GoStruct)
+			_, err := NewProtoBytes([]byte(tt.proto)).Build(tracker{name: rootName})
+			if tt.expErr {
+				if err == nil {
+					t.Errorf("TC: %s: Expected error but did not get any error", tt.tc)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("TC: %s: Did not expect error but got %+v", tt.tc, err)
+			}
+			var buf bytes.Buffer
+			if err = WriteStructs(&buf); err != nil {
+				t.Fatalf("TC: %s: WriteStructs failed: %+v", tt.tc, err)
+			}
+			for _, exp := range tt.exp {
+				if !strings.Contains(buf.String(), exp) {
+					t.Errorf("TC: %s: Expected %q in the structs but got:\n%s", tt.tc, exp, buf.String())
+				}
+			}
+		})
+	}
+}
+
+func TestParse_Proto(t *testing.T) {
+	dec, err := NewFileDecoder("", "samples/proto/order.proto")
+	if err != nil {
+		t.Fatalf("NewFileDecoder failed: %+v", err)
+	}
+	if err = Parse(dec); err != nil {
+		t.Fatalf("Parse failed: %+v", err)
+	}
+	var buf bytes.Buffer
+	if err = WriteStructs(&buf); err != nil {
+		t.Fatalf("WriteStructs failed: %+v", err)
+	}
+	out := buf.String()
+	for _, exp := range []string{
+		"type Order struct {",
+		"\t// Unique id of the order\n\tID int64 `json:\"id,string,omitempty\"`",
+		"\t// One of: STATUS_UNSPECIFIED, STATUS_PAID, STATUS_SHIPPED\n\tStatus string `json:\"status,omitempty\"`",
+		"\tPlacedAt time.Time `json:\"placedAt,omitempty\"`",
+		"\tCustomer *Customer `json:\"customer,omitempty\"`",
+		"\tLines []OrderLine `json:\"lines,omitempty\"`",
+		"\tLabels map[string]string `json:\"labels,omitempty\"`",
+		"\tCoupon *string `json:\"coupon,omitempty\"`",
+		"\tNote *string `json:\"note,omitempty\"`",
+		"\tExternalRef string `json:\"ref,omitempty\"`",
+		"\t// fixed64 encoded as a string\n\tParcelIds []string `json:\"parcelIds,omitempty\"`",
+		"\t// Only one of card, voucher_code is set\n\tCard *OrderCard `json:\"card,omitempty\"`",
+		"\tVoucherCode *string `json:\"voucherCode,omitempty\"`",
+		"type OrderLine struct {",
+		"\tQuantity int64 `json:\"quantity,omitempty\"`",
+		"\tExpiryMonth int `json:\"expiryMonth,omitempty\"`",
+		"\tAvatar []byte `json:\"avatar,omitempty\"`",
+		"\tReferrer *Customer `json:\"referrer,omitempty\"`",
+	} {
+		if !strings.Contains(out, exp) {
+			t.Errorf("Expected %q in the structs but got:\n%s", exp, out)
+		}
+	}
+}
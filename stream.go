@@ -0,0 +1,185 @@
+package togo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+)
+
+// StreamValues reads every top level value of the TokenStream and grows the
+// root type described by the tracker with each of them.
+func StreamValues(ts TokenStream, tr tracker) (*GoStruct, int, error) {
+	var gs *GoStruct
+	nest := tr.nesting
+	for ts.More() {
+		tok, err := ts.Token()
+		if err != nil {
+			log.Printf("Error while reading token: %+v\n", err)
+			return nil, 0, err
+		}
+		switch tok {
+		case json.Delim('{'):
+			gs, err = StreamMap(ts, tr)
+		case json.Delim('['):
+			gs, nest, err = StreamSlice(ts, tr)
+		default:
+			log.Println("Unknown type to decode", tok)
+			return nil, 0, errors.New("Unknown type to decode")
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+	return gs, nest, nil
+}
+
+// StreamMap is the streaming counterpart of HandleMap. It converts the
+// object whose opening '{' has just been read from the TokenStream into
+// a GoStruct.
+func StreamMap(ts TokenStream, tr tracker) (*GoStruct, error) {
+	log.Printf("Tracking streamed map element: %+v \n", tr)
+	trackerCache[tr.name] = &tr
+
+	gs := new(GoStruct)
+	gs.Name = tr.name
+	gs.Level = tr.level
+	gs.mixed = tr.mixed
+
+	for ts.More() {
+		tok, err := ts.Token()
+		if err != nil {
+			log.Printf("Error while reading key: %+v\n", err)
+			return nil, err
+		}
+		key, ok := tok.(string)
+		if !ok {
+			return nil, fmt.Errorf("Expected an object key but found %v", tok)
+		}
+		field, err := streamField(ts, key, tr)
+		if err != nil {
+			return nil, err
+		}
+		field.Annotate(tr.annotate(key))
+		field.addSource(tr.source)
+		if err = gs.AddField(field); err != nil {
+			log.Printf("Could not add field %s: %+v\n", key, err)
+			return nil, err
+		}
+	}
+	if _, err := ts.Token(); err != nil {
+		return nil, err
+	}
+	err := Cache(gs)
+	if err != nil {
+		log.Printf("Error while caching: %+v\n", err)
+		return nil, err
+	}
+	return gs, nil
+}
+
+// streamField reads the value of the key and converts it into a Field
+func streamField(ts TokenStream, key string, tr tracker) (*Field, error) {
+	tok, err := ts.Token()
+	if err != nil {
+		log.Printf("Error while reading value of %s: %+v\n", key, err)
+		return nil, err
+	}
+	switch tok {
+	case json.Delim('{'):
+		ctr := tr.clone()
+		ctr.name = tr.structName(key)
+		ctr.nesting = -1
+		ctr.level = tr.level + 1
+		cgs, err := StreamMap(ts, ctr)
+		if err != nil {
+			return nil, err
+		}
+		return &Field{name: key, dataType: Map, dtStruct: cgs.Name, sliceNesting: -1}, nil
+	case json.Delim('['):
+		ctr := tr.clone()
+		ctr.name = tr.structName(key)
+		ctr.nesting = 1
+		cgs, nest, err := StreamSlice(ts, ctr)
+		if err != nil {
+			return nil, err
+		}
+		return &Field{name: key, dataType: Slice, dtStruct: cgs.Name, sliceNesting: nest}, nil
+	}
+	field, err := ToField(key, tok)
+	if err != nil {
+		return nil, err
+	}
+	field.sliceNesting = -1
+	return field, nil
+}
+
+// StreamSlice is the streaming counterpart of HandleSlice. It converts the
+// array whose opening '[' has just been read from the TokenStream. Every
+// element is folded into the GoStruct of the elements as soon as it is read,
+// so an array is never held in memory.
+func StreamSlice(ts TokenStream, tr tracker) (*GoStruct, int, error) {
+	log.Printf("Tracker for streamed slice: %+v \n", tr)
+	trackerCache[tr.name] = &tr
+
+	nest := tr.nesting
+	var elem *Field
+	gs := new(GoStruct)
+
+	for ts.More() {
+		tok, err := ts.Token()
+		if err != nil {
+			log.Printf("Error while reading element: %+v\n", err)
+			return nil, 0, err
+		}
+		var field *Field
+		var chgs *GoStruct
+		switch tok {
+		case json.Delim('{'):
+			ctr := tr.clone()
+			ctr.nesting = -1
+			chgs, err = StreamMap(ts, ctr)
+			field = &Field{name: tr.name, dataType: Map}
+		case json.Delim('['):
+			ctr := tr.clone()
+			ctr.nesting = tr.nesting + 1
+			var cnest int
+			chgs, cnest, err = StreamSlice(ts, ctr)
+			field = &Field{name: tr.name, dataType: Slice}
+			// An empty inner slice gives way to the other inner slices
+			if err == nil && chgs.Name == anyType && !gs.IsEmpty() {
+				chgs = nil
+			} else {
+				nest = cnest
+			}
+		default:
+			field, err = ToField(tr.name, tok)
+		}
+		if err != nil {
+			log.Printf("Could not convert the element to GoStruct: %+v\n", err)
+			return nil, 0, err
+		}
+
+		if elem == nil {
+			elem = field
+		} else if !elem.merge(field) {
+			log.Printf("Different data-types found inside a list. Expected: %+v, Found: %+v\n",
+				elem.dataType, field.dataType)
+			return nil, 0, errors.New("Slice not feasible. Found different data-types")
+		}
+		if chgs == nil {
+			continue
+		}
+		if gs.IsEmpty() || gs.Name == anyType {
+			gs = chgs
+		} else if err = gs.Grow(chgs); err != nil {
+			log.Printf("Cannot group the existing GoStruct due to %+v \n", err)
+			return nil, 0, err
+		}
+	}
+	if _, err := ts.Token(); err != nil {
+		return nil, 0, err
+	}
+	gs = elemStruct(gs, elem)
+	return gs, nest, nil
+}
@@ -0,0 +1,179 @@
+package togo
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParse_Stream(t *testing.T) {
+	files := []string{
+		"samples/json/twitter.json",
+		"samples/json/youtube.json",
+		"samples/json/users.json",
+	}
+	for _, file := range files {
+		t.Run(file, func(t *testing.T) {
+			// Decoder hides the Stream method and forces the materialised path
+			if err := Parse(struct{ Decoder }{&JSON{File: file}}); err != nil {
+				t.Fatalf("TC: %s: Parse failed: %+v", file, err)
+			}
+			decoded := NameStructCache
+
+			if err := Parse(&JSON{File: file}); err != nil {
+				t.Fatalf("TC: %s: Parse failed: %+v", file, err)
+			}
+			streamed := NameStructCache
+
+			if len(decoded) != len(streamed) {
+				t.Fatalf("TC: %s: Expected %d structs but streamed %d", file,
+					len(decoded), len(streamed))
+			}
+			for name, gs := range decoded {
+				sgs, ok := streamed[name]
+				if !ok {
+					t.Errorf("TC: %s: Struct %s was not streamed", file, name)
+					continue
+				}
+				for fn, fld := range gs.Fields {
+					sfld, ok := sgs.Fields[fn]
+					if !ok || sfld.dataType != fld.dataType || sfld.dtStruct != fld.dtStruct {
+						t.Errorf("TC: %s: Field %s.%s differs, decoded %+v streamed %+v",
+							file, name, fn, fld, sfld)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestParse_StreamEmptyInnerSlice(t *testing.T) {
+	tests := []struct {
+		tc   string
+		data string
+		exp  string
+	}{
+		{"Root Slice", `[[1,2],[]]`, ""},
+		{"Empty First", `{"a":[[],[1,2]]}`, "\tA [][]float64 `json:\"a\"`"},
+		{"Slice Of Maps", `{"a":[[{"x":1}],[]]}`, "\tA [][]A `json:\"a\"`"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tc, func(t *testing.T) {
+			var outs []string
+			for _, dec := range []Decoder{
+				struct{ DecodeAnnotater }{NewJSONBytes([]byte(tt.data))},
+				NewJSONBytes([]byte(tt.data)),
+			} {
+				if err := Parse(dec); err != nil {
+					t.Fatalf("TC: %s: Parse failed: %+v", tt.tc, err)
+				}
+				var buf bytes.Buffer
+				if err := WriteStructs(&buf); err != nil {
+					t.Fatalf("TC: %s: WriteStructs failed: %+v", tt.tc, err)
+				}
+				outs = append(outs, buf.String())
+			}
+			if outs[0] != outs[1] {
+				t.Errorf("TC: %s: Expected the same structs but decoded:\n%s\nstreamed:\n%s",
+					tt.tc, outs[0], outs[1])
+			}
+			if !strings.Contains(outs[1], tt.exp) {
+				t.Errorf("TC: %s: Expected %q in the structs but got:\n%s", tt.tc, tt.exp, outs[1])
+			}
+		})
+	}
+}
+
+// writeRecords writes a JSON array of n records into a temporary file
+func writeRecords(b *testing.B, n int) string {
+	f, err := ioutil.TempFile("", "togo-bench-*.json")
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer f.Close()
+	w := bufio.NewWriter(f)
+	fmt.Fprint(w, "[")
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			fmt.Fprint(w, ",")
+		}
+		fmt.Fprintf(w, `{"id":%d,"name":"user-%d","active":%v,"tags":["a","b"],`+
+			`"address":{"city":"city-%d","zip":"%05d"}}`, i, i, i%2 == 0, i, i)
+	}
+	fmt.Fprint(w, "]")
+	if err = w.Flush(); err != nil {
+		b.Fatal(err)
+	}
+	return f.Name()
+}
+
+// peakHeap runs fn and returns the highest heap allocation seen meanwhile
+func peakHeap(fn func()) uint64 {
+	var peak uint64
+	done := make(chan bool)
+	go func() {
+		var ms runtime.MemStats
+		for {
+			runtime.ReadMemStats(&ms)
+			if ms.HeapAlloc > peak {
+				peak = ms.HeapAlloc
+			}
+			select {
+			case <-done:
+				done <- true
+				return
+			case <-time.After(time.Millisecond):
+			}
+		}
+	}()
+	fn()
+	done <- true
+	<-done
+	return peak
+}
+
+func benchmarkParse(b *testing.B, records int, stream bool) {
+	file := writeRecords(b, records)
+	defer os.Remove(file)
+	st, err := os.Stat(file)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	log.SetOutput(ioutil.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	var dec Decoder = &JSON{File: file}
+	if !stream {
+		dec = struct{ Decoder }{dec}
+	}
+	var peak uint64
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		runtime.GC()
+		p := peakHeap(func() {
+			if err := Parse(dec); err != nil {
+				b.Fatal(err)
+			}
+		})
+		if p > peak {
+			peak = p
+		}
+	}
+	b.ReportMetric(float64(st.Size())/(1<<20), "input-MB")
+	b.ReportMetric(float64(peak)/(1<<20), "peak-heap-MB")
+}
+
+func BenchmarkParse_Stream_10k(b *testing.B)   { benchmarkParse(b, 10000, true) }
+func BenchmarkParse_Stream_100k(b *testing.B)  { benchmarkParse(b, 100000, true) }
+func BenchmarkParse_Stream_1M(b *testing.B)    { benchmarkParse(b, 1000000, true) }
+func BenchmarkParse_Decoded_10k(b *testing.B)  { benchmarkParse(b, 10000, false) }
+func BenchmarkParse_Decoded_100k(b *testing.B) { benchmarkParse(b, 100000, false) }
+func BenchmarkParse_Decoded_1M(b *testing.B)   { benchmarkParse(b, 1000000, false) }
@@ -2,35 +2,262 @@ package togo
 
 import (
 	"fmt"
+	"io"
+	"sort"
 	"strings"
+	"unicode"
 )
 
+// anyType is the go type used for values whose type cannot be inferred
+const anyType = "interface{}"
+
+// initialisms are the common abbreviations that golint wants to be
+// written in upper case inside identifiers, along with a few more that are
+// common in configuration keys (e.g. DB).
+var initialisms = map[string]bool{
+	"ACL": true, "API": true, "ASCII": true, "CPU": true, "CSS": true,
+	"DB": true, "DNS": true, "EOF": true, "GUID": true, "HTML": true,
+	"HTTP": true, "HTTPS": true, "ID": true, "IP": true, "JSON": true,
+	"LHS": true, "QPS": true, "RAM": true, "RHS": true, "RPC": true,
+	"SLA": true, "SMTP": true, "SQL": true, "SSH": true, "TCP": true,
+	"TLS": true, "TTL": true, "UDP": true, "UI": true, "UID": true,
+	"URI": true, "URL": true, "UTF8": true, "UUID": true, "VM": true,
+	"XML": true, "XMPP": true, "XSRF": true, "XSS": true, "YAML": true,
+}
+
+// goType returns the go type of a primitive FieldDT.
+func (f FieldDT) goType() string {
+	switch f {
+	case Bool:
+		return "bool"
+	case Int:
+		return "int"
+	case Int64:
+		return "int64"
+	case Float64:
+		return "float64"
+	case String:
+		return "string"
+	default:
+		return anyType
+	}
+}
+
+// goName converts a key from the decoded data into an exported go identifier,
+// e.g. "first_name" becomes "FirstName" and "user-id" becomes "UserID".
+func goName(key string) string {
+	words := nameWords(key)
+	var sb strings.Builder
+	for _, w := range words {
+		if up := strings.ToUpper(w); initialisms[up] {
+			sb.WriteString(up)
+			continue
+		}
+		rs := []rune(w)
+		rs[0] = unicode.ToUpper(rs[0])
+		sb.WriteString(string(rs))
+	}
+	name := sb.String()
+	if name == "" {
+		return "Field"
+	}
+	if unicode.IsDigit([]rune(name)[0]) {
+		name = "X" + name
+	}
+	return name
+}
+
+// nameWords returns the words of a key or of a name given in the input,
+// e.g. the title of a schema, split at anything but letters and digits
+func nameWords(name string) []string {
+	return strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// typeName returns the go type to use when referring to dtStruct. Structs
+// are referred to by their exported name while go types are kept verbatim.
+// Pointers, slices, arrays and maps with string keys of either are spelled
+// out in dtStruct, e.g. "map[string]*address" for map[string]*Address.
+func typeName(dtStruct string) string {
+	switch dtStruct {
+	case "bool", "byte", "int", "int64", "float64", "string", anyType:
+		return dtStruct
+	}
+	if isNamedType(dtStruct) {
+		return dtStruct
+	}
+	switch {
+	case strings.HasPrefix(dtStruct, "*"):
+		return "*" + typeName(dtStruct[1:])
+	case strings.HasPrefix(dtStruct, "map[string]"):
+		return "map[string]" + typeName(dtStruct[len("map[string]"):])
+	case strings.HasPrefix(dtStruct, "["):
+		end := strings.IndexByte(dtStruct, ']')
+		return dtStruct[:end+1] + typeName(dtStruct[end+1:])
+	}
+	return goName(dtStruct)
+}
+
+// tag renders an annotation of the form "key:value key2:value2" as a go
+// struct tag, i.e. `key:"value" key2:"value2"`. A word without a colon
+// belongs to the value before it, as in the namespaced "xml:space local".
+func tag(annotation string) string {
+	var parts []string
+	for _, a := range strings.Fields(annotation) {
+		if !strings.Contains(a, ":") && len(parts) > 0 {
+			parts[len(parts)-1] += " " + a
+			continue
+		}
+		parts = append(parts, a)
+	}
+	var tags []string
+	for _, p := range parts {
+		kv := strings.SplitN(p, ":", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		tags = append(tags, fmt.Sprintf("%s:%q", kv[0], kv[1]))
+	}
+	if len(tags) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" `%s`", strings.Join(tags, " "))
+}
+
 // ToStruct converts the given instance of GoStruct into the actual go code for the struct.
 func (gs GoStruct) ToStruct() string {
 	var buf []string
-	buf = append(buf, fmt.Sprintf("type %s struct {", gs.Name))
+	buf = append(buf, fmt.Sprintf("type %s struct {", goName(gs.Name)))
+
+	names := make([]string, 0, len(gs.Fields))
+	for n := range gs.Fields {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	// The inputs the struct was seen in, to tell the fields missing from
+	// some of them
+	srcs := make(map[string]bool)
 	for _, fld := range gs.Fields {
+		for _, src := range fld.sources {
+			srcs[src] = true
+		}
+	}
+
+	// Keys such as user_id and userId fold into the same go name, the later
+	// ones are told apart by a numeric suffix while their tags keep the key.
+	used := make(map[string]bool, len(names))
+	for _, n := range names {
+		fld := gs.Fields[n]
+		name := goName(fld.name)
+		for i := 2; used[name]; i++ {
+			name = fmt.Sprintf("%s%d", goName(fld.name), i)
+		}
+		used[name] = true
 		var tp string
 
 		switch fld.dataType {
-		case Bool:
-			tp = "bool"
-		case Int:
-			tp = "int"
-		case Int64:
-			tp = "int64"
-		case Float64:
-			tp = "float64"
-		case String:
-			tp = "string"
 		case Slice:
 			nest := strings.Repeat("[]", fld.sliceNesting)
-			tp = fmt.Sprintf("%s%s", nest, fld.dtStruct)
-		case Map:
-			tp = fld.dtStruct
+			tp = fmt.Sprintf("%s%s", nest, typeName(fld.dtStruct))
+		case Map, Named:
+			tp = typeName(fld.dtStruct)
+		default:
+			tp = fld.dataType.goType()
+		}
+		if fld.comment != "" {
+			for _, l := range strings.Split(fld.comment, "\n") {
+				buf = append(buf, strings.TrimRight("\t// "+l, " "))
+			}
 		}
-		buf = append(buf, fmt.Sprintf("%s %s", fld.name, tp))
+		if len(fld.sources) > 0 && len(fld.sources) < len(srcs) {
+			buf = append(buf, "\t// from: "+strings.Join(fld.sources, ", "))
+		}
+		buf = append(buf, fmt.Sprintf("\t%s %s%s", name, tp, tag(fld.annotation)))
 	}
 	buf = append(buf, "}")
 	return strings.Join(buf, "\n")
 }
+
+// typeImports maps the packages of the namedTypes onto their import path
+var typeImports = map[string]string{
+	"time": "time",
+	"xml":  "encoding/xml",
+	"big":  "math/big",
+}
+
+// baseType returns the type dtStruct is made of once its pointers, slices,
+// arrays and maps are removed, e.g. "big.Rat" for "map[string]*big.Rat"
+func baseType(dtStruct string) string {
+	for {
+		switch {
+		case strings.HasPrefix(dtStruct, "*"):
+			dtStruct = dtStruct[1:]
+		case strings.HasPrefix(dtStruct, "map[string]"):
+			dtStruct = dtStruct[len("map[string]"):]
+		case strings.HasPrefix(dtStruct, "["):
+			dtStruct = dtStruct[strings.IndexByte(dtStruct, ']')+1:]
+		default:
+			return dtStruct
+		}
+	}
+}
+
+// imports returns the sorted import paths of the packages of the named types
+// used by the structs inferred by the last Parse
+func imports() []string {
+	seen := make(map[string]bool)
+	var paths []string
+	for _, gsl := range LevelOrderCache {
+		for _, gs := range gsl {
+			for _, fld := range gs.Fields {
+				bt := baseType(fld.dtStruct)
+				if !isNamedType(bt) && !isNamedType("*"+bt) {
+					continue
+				}
+				idx := strings.IndexByte(bt, '.')
+				if idx < 0 {
+					continue
+				}
+				if path, ok := typeImports[bt[:idx]]; ok && !seen[path] {
+					seen[path] = true
+					paths = append(paths, path)
+				}
+			}
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// WriteStructs writes the go code of every struct inferred by the last Parse
+// to w, level by level starting with the root struct, after the imports of
+// the packages of the types they use. Structs are cached after the structs
+// nested in them, so every level is written in reverse.
+func WriteStructs(w io.Writer) error {
+	if paths := imports(); len(paths) > 0 {
+		lines := []string{"import ("}
+		for _, path := range paths {
+			lines = append(lines, fmt.Sprintf("\t%q", path))
+		}
+		lines = append(lines, ")")
+		if _, err := fmt.Fprintf(w, "%s\n\n", strings.Join(lines, "\n")); err != nil {
+			return err
+		}
+	}
+	levels := make([]int, 0, len(LevelOrderCache))
+	for lvl := range LevelOrderCache {
+		levels = append(levels, lvl)
+	}
+	sort.Ints(levels)
+	for _, lvl := range levels {
+		gsl := LevelOrderCache[lvl]
+		for i := len(gsl) - 1; i >= 0; i-- {
+			if _, err := fmt.Fprintf(w, "%s\n\n", gsl[i].ToStruct()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
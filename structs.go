@@ -1,10 +1,14 @@
 package togo
 
 import (
+	"encoding/xml"
 	"fmt"
 	"log"
+	"math/big"
 	"reflect"
+	"sort"
 	"strings"
+	"time"
 )
 
 // FieldDT is an alias for the field data type represented
@@ -17,6 +21,12 @@ type FieldDT uint
 //   2. Similar float32 is widened to float64 in the struct.
 //   3. Two complex data type supported are Slice and Map. All maps and slices would be
 //		represented by these two consts always.
+//   4. A null value carries no type information and is represented as Interface until
+//		another sample tells us better.
+//   5. Scalars that are not covered by the kinds above (e.g. time.Time) are Named and
+//		carry their go type in the dtStruct of the Field.
+//   6. Unsigned integers are only told apart by some formats and are Named uint64.
+//		Together with signed integers they widen to Int64.
 const (
 	Initial = iota
 	Bool
@@ -26,17 +36,66 @@ const (
 	String
 	Slice
 	Map
+	Interface
+	Named
 )
 
+// namedTypes lists the go types, beyond the kinds understood by toFieldDT,
+// that a decoder can produce for a scalar value along with how the type is
+// spelled in the generated struct.
+var namedTypes = map[reflect.Type]string{
+	reflect.TypeOf(time.Time{}):      "time.Time",
+	reflect.TypeOf(time.Duration(0)): "time.Duration",
+	reflect.TypeOf(xml.Name{}):       "xml.Name",
+	reflect.TypeOf(uint64(0)):        "uint64",
+	reflect.TypeOf([]byte{}):         "[]byte",
+	reflect.TypeOf(&big.Int{}):       "*big.Int",
+	reflect.TypeOf(&big.Rat{}):       "*big.Rat",
+}
+
+// registerNamedType adds the go type of val to the namedTypes, spelled tn in
+// the generated struct, for the decoders producing types of their own. The
+// path is the import path of the package of tn.
+func registerNamedType(val interface{}, tn, path string) {
+	namedTypes[reflect.TypeOf(val)] = tn
+	if idx := strings.IndexByte(baseType(tn), '.'); idx >= 0 {
+		typeImports[baseType(tn)[:idx]] = path
+	}
+}
+
+// isNamedType checks if the type name is one of the namedTypes
+func isNamedType(tn string) bool {
+	for _, n := range namedTypes {
+		if n == tn {
+			return true
+		}
+	}
+	return false
+}
+
 func (f FieldDT) primitive() bool {
 	switch f {
-	case Int, Bool, Int64, Float64, String:
+	case Int, Bool, Int64, Float64, String, Interface, Named:
 		return true
 	default:
 		return false
 	}
 }
 
+func (f FieldDT) numeric() bool {
+	switch f {
+	case Int, Int64, Float64:
+		return true
+	default:
+		return false
+	}
+}
+
+// numeric checks if the field holds a number, signed or unsigned
+func (f *Field) numeric() bool {
+	return f.dataType.numeric() || (f.dataType == Named && f.dtStruct == "uint64")
+}
+
 func (f FieldDT) str() string {
 	switch f {
 	case Initial:
@@ -55,6 +114,10 @@ func (f FieldDT) str() string {
 		return "Slice"
 	case Map:
 		return "Map"
+	case Interface:
+		return "Interface"
+	case Named:
+		return "Named"
 	default:
 		return "Unknown Type"
 	}
@@ -75,6 +138,8 @@ func toFieldDT(k reflect.Kind) (FieldDT, bool) {
 		return Slice, true
 	} else if k == reflect.Bool {
 		return Bool, true
+	} else if k == reflect.Invalid {
+		return Interface, true
 	} else {
 		return Initial, false
 	}
@@ -89,6 +154,8 @@ type Field struct {
 	dataType     FieldDT
 	dtStruct     string
 	sliceNesting int
+	sources      []string
+	comment      string
 }
 
 // Equals check if this instance of field is "in-principle"
@@ -109,6 +176,74 @@ func (f *Field) Equals(of *Field) bool {
 	return true
 }
 
+// elemType returns the dtStruct that a slice of values like this field has.
+func (f *Field) elemType() string {
+	switch f.dataType {
+	case Map, Named:
+		return f.dtStruct
+	case Slice:
+		return strings.Repeat("[]", f.sliceNesting) + f.dtStruct
+	default:
+		return f.dataType.goType()
+	}
+}
+
+// merge widens this field so that it can also hold the other field.
+// A field of type Interface (a null sample) gives way to any other type,
+// a single value gives way to a slice of the same values and numeric types
+// widen to the larger one, with unsigned and signed integers widening to
+// Int64. It returns false if the two fields cannot be
+// reconciled.
+func (f *Field) merge(of *Field) bool {
+	if f.Equals(of) || of.dataType == Interface {
+		return true
+	}
+	if f.dataType == Slice && f.sliceNesting == 1 && f.dtStruct == of.elemType() {
+		return true
+	}
+	if of.dataType == Slice && of.sliceNesting == 1 && of.dtStruct == f.elemType() {
+		f.dataType = Slice
+		f.dtStruct = of.dtStruct
+		f.sliceNesting = 1
+		return true
+	}
+	if of.dataType == Slice && of.dtStruct == anyType {
+		return f.dataType == Slice
+	}
+	if f.dataType == Interface || (f.dataType == Slice && f.dtStruct == anyType &&
+		of.dataType == Slice) {
+		f.dataType = of.dataType
+		f.dtStruct = of.dtStruct
+		f.sliceNesting = of.sliceNesting
+		return true
+	}
+	if f.name != of.name || !f.numeric() || !of.numeric() {
+		return false
+	}
+	if f.dataType == Named {
+		f.dataType = Int64
+		f.dtStruct = ""
+	}
+	if of.dataType > f.dataType && of.dataType != Named {
+		f.dataType = of.dataType
+	} else if of.dataType == Named && f.dataType < Int64 {
+		f.dataType = Int64
+	}
+	return true
+}
+
+// mix makes the field hold values of any type, for the scalars that the
+// samples of a mixed GoStruct disagree on. It returns false unless both
+// fields are scalars.
+func (f *Field) mix(of *Field) bool {
+	if !f.dataType.primitive() || !of.dataType.primitive() {
+		return false
+	}
+	f.dataType = Named
+	f.dtStruct = anyType
+	return true
+}
+
 // Annotate adds an annotation to the field
 func (f *Field) Annotate(a string) {
 	if f.annotation == "" {
@@ -120,10 +255,39 @@ func (f *Field) Annotate(a string) {
 	}
 }
 
+// Comment sets the doc comment of the field, unless it already has one
+func (f *Field) Comment(c string) {
+	if f.comment == "" {
+		f.comment = c
+	}
+}
+
+// Sources returns the inputs (e.g. files) whose data contributed this field
+func (f *Field) Sources() []string {
+	return f.sources
+}
+
+// addSource records that the field was seen in the given inputs
+func (f *Field) addSource(srcs ...string) {
+	for _, src := range srcs {
+		if src == "" {
+			continue
+		}
+		idx := sort.SearchStrings(f.sources, src)
+		if idx < len(f.sources) && f.sources[idx] == src {
+			continue
+		}
+		f.sources = append(f.sources, "")
+		copy(f.sources[idx+1:], f.sources[idx:])
+		f.sources[idx] = src
+	}
+}
+
 // Clones a field. Visible for testing
 func (f *Field) clone() Field {
 	return Field{
 		f.name, f.annotation, f.dataType, f.dtStruct, f.sliceNesting,
+		append([]string(nil), f.sources...), f.comment,
 	}
 }
 
@@ -133,6 +297,12 @@ func ToField(name string, val interface{}) (*Field, error) {
 	f := new(Field)
 	// TODO: To work on normalizing the name
 	f.name = name
+	if tn, ok := namedTypes[reflect.TypeOf(val)]; ok {
+		f.dataType = Named
+		f.dtStruct = tn
+		log.Printf("Field created: %+v \n", f)
+		return f, nil
+	}
 	k := reflect.ValueOf(val).Kind()
 	dt, ok := toFieldDT(k)
 	if !ok {
@@ -154,6 +324,9 @@ type GoStruct struct {
 	Name   string
 	Fields map[string]*Field
 	Level  int
+	// mixed is set if the samples of the struct may disagree on the types
+	// of its fields, see MixedTyper
+	mixed bool
 }
 
 // Clone deep clones a GoStruct. Visible for testing
@@ -162,6 +335,7 @@ func (gs GoStruct) clone() GoStruct {
 		Name:   gs.Name,
 		Fields: make(map[string]*Field),
 		Level:  gs.Level,
+		mixed:  gs.mixed,
 	}
 	for n, f := range gs.Fields {
 		nf := f.clone()
@@ -195,6 +369,8 @@ func (gs *GoStruct) AddField(f *Field) error {
 		}
 	}
 	f.Annotate(exFld.annotation)
+	f.addSource(exFld.sources...)
+	f.Comment(exFld.comment)
 	gs.Fields[f.name] = f
 	log.Printf("Added field %+v to the GoStruct %+v", f.name, gs.Name)
 	return nil
@@ -229,12 +405,15 @@ func (gs *GoStruct) Grow(other *GoStruct) error {
 			gs.AddField(field)
 			continue
 		}
-		if feq := gfield.Equals(field); !feq {
+		mixed := gs.mixed || other.mixed
+		if ok := gfield.merge(field) || (mixed && gfield.mix(field)); !ok {
 			return GoStructError{
 				gs:      *gs,
 				message: fmt.Sprintf("Field %s does not equal, cannot Grow", gfield.name),
 			}
 		}
+		gfield.addSource(field.sources...)
+		gfield.Comment(field.comment)
 	}
 	return nil
 }
@@ -94,28 +94,44 @@ func TestField_Equals(t *testing.T) {
 		{
 			tc: "Name Not Equal",
 			field: Field{
-				"Random", field.annotation, field.dataType, field.dtStruct, field.sliceNesting,
+				name:         "Random",
+				annotation:   field.annotation,
+				dataType:     field.dataType,
+				dtStruct:     field.dtStruct,
+				sliceNesting: field.sliceNesting,
 			},
 			equals: false,
 		},
 		{
 			tc: "Type Not Equal",
 			field: Field{
-				field.name, field.annotation, Slice, field.dtStruct, field.sliceNesting,
+				name:         field.name,
+				annotation:   field.annotation,
+				dataType:     Slice,
+				dtStruct:     field.dtStruct,
+				sliceNesting: field.sliceNesting,
 			},
 			equals: false,
 		},
 		{
 			tc: "DTStruct Not Equal",
 			field: Field{
-				field.name, field.annotation, Map, "BarType", field.sliceNesting,
+				name:         field.name,
+				annotation:   field.annotation,
+				dataType:     Map,
+				dtStruct:     "BarType",
+				sliceNesting: field.sliceNesting,
 			},
 			equals: false,
 		},
 		{
 			tc: "Nesting Not Equal",
 			field: Field{
-				field.name, field.annotation, Map, field.dtStruct, 1,
+				name:         field.name,
+				annotation:   field.annotation,
+				dataType:     Map,
+				dtStruct:     field.dtStruct,
+				sliceNesting: 1,
 			},
 			equals: false,
 		},
@@ -592,3 +608,33 @@ func TestGoStruct_IsEmpty(t *testing.T) {
 		})
 	}
 }
+
+func TestField_merge(t *testing.T) {
+	tests := []struct {
+		tc     string
+		field  Field
+		other  Field
+		result FieldDT
+		merged bool
+	}{
+		{"Same Type", createNamedField("Foo", Int, "", 0), createNamedField("Foo", Int, "", 0), Int, true},
+		{"Null Existing", createNamedField("Foo", Interface, "", 0), createNamedField("Foo", String, "", 0), String, true},
+		{"Null Other", createNamedField("Foo", Bool, "", 0), createNamedField("Foo", Interface, "", 0), Bool, true},
+		{"Widen Numeric", createNamedField("Foo", Int, "", 0), createNamedField("Foo", Float64, "", 0), Float64, true},
+		{"Incompatible", createNamedField("Foo", Int, "", 0), createNamedField("Foo", String, "", 0), Int, false},
+		{"Unsigned And Signed", createNamedField("Foo", Named, "uint64", 0), createNamedField("Foo", Int, "", 0), Int64, true},
+		{"Signed And Unsigned", createNamedField("Foo", Int, "", 0), createNamedField("Foo", Named, "uint64", 0), Int64, true},
+		{"Unsigned And Float", createNamedField("Foo", Named, "uint64", 0), createNamedField("Foo", Float64, "", 0), Float64, true},
+		{"Slice And Empty Slice", createNamedField("Foo", Slice, "string", 1), createNamedField("Foo", Slice, anyType, 1), Slice, true},
+		{"Bytes And Int", createNamedField("Foo", Named, "[]byte", 0), createNamedField("Foo", Int, "", 0), Named, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tc, func(t *testing.T) {
+			merged := tt.field.merge(&tt.other)
+			if merged != tt.merged || tt.field.dataType != tt.result {
+				t.Errorf("TC: %s: Expected (%v, %v) but got (%v, %v)", tt.tc,
+					tt.result, tt.merged, tt.field.dataType, merged)
+			}
+		})
+	}
+}
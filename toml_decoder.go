@@ -0,0 +1,106 @@
+package togo
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"math"
+	"time"
+
+	"github.com/pelletier/go-toml"
+)
+
+// TOML type structure to convert to go struct. TOML values are typed, so
+// integers, floats and date-times are kept apart instead of being flattened
+// into float64 and string as the JSON path does.
+type TOML struct {
+	File   string
+	reader io.Reader
+}
+
+// NewTOMLReader creates a TOML decoder reading from r instead of a file.
+// The reader is consumed by the first Decode.
+func NewTOMLReader(r io.Reader) *TOML {
+	return &TOML{reader: r}
+}
+
+// NewTOMLBytes creates a TOML decoder for the in-memory data
+func NewTOMLBytes(b []byte) *TOML {
+	return NewTOMLReader(bytes.NewReader(b))
+}
+
+// Decode this TOML instance into decodedData
+func (t *TOML) Decode() (DecodedData, error) {
+
+	dd := new(DecodedData)
+	f, err := open(t.File, t.reader)
+	if err != nil {
+		log.Println("Error while reading file", err)
+		return *dd, err
+	}
+	defer f.Close()
+
+	tree, err := toml.LoadReader(f)
+	if err != nil {
+		log.Println("Error while decoding", err)
+		return *dd, err
+	}
+	dd.mapData = normalizeTOML(tree.ToMap()).(map[string]interface{})
+	return *dd, nil
+}
+
+// Source of this TOML instance, the file name or empty if read from a reader
+func (t *TOML) Source() string {
+	return t.File
+}
+
+// Annotate a field name with its toml tag
+func (t *TOML) Annotate(name string) string {
+	return "toml:" + name
+}
+
+// normalizeTOML converts the values produced by go-toml into the types
+// understood by ToField. Integers that fit into 32 bits become int and the
+// rest stay int64; offset and local date-times as well as local dates
+// become time.Time, while a local time of day is kept as its string form.
+func normalizeTOML(val interface{}) interface{} {
+	switch v := val.(type) {
+	case map[string]interface{}:
+		for k, e := range v {
+			v[k] = normalizeTOML(e)
+		}
+		return v
+	case *toml.Tree:
+		return normalizeTOML(v.ToMap())
+	case []*toml.Tree:
+		sl := make([]interface{}, 0, len(v))
+		for _, e := range v {
+			sl = append(sl, normalizeTOML(e.ToMap()))
+		}
+		return sl
+	case []map[string]interface{}:
+		sl := make([]interface{}, 0, len(v))
+		for _, e := range v {
+			sl = append(sl, normalizeTOML(e))
+		}
+		return sl
+	case []interface{}:
+		for i, e := range v {
+			v[i] = normalizeTOML(e)
+		}
+		return v
+	case int64:
+		if v >= math.MinInt32 && v <= math.MaxInt32 {
+			return int(v)
+		}
+		return v
+	case toml.LocalDate:
+		return v.In(time.UTC)
+	case toml.LocalDateTime:
+		return v.In(time.UTC)
+	case toml.LocalTime:
+		return v.String()
+	default:
+		return v
+	}
+}
@@ -0,0 +1,71 @@
+package togo
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestTOML_Decode(t *testing.T) {
+	tm := &TOML{File: "samples/toml/config.toml"}
+	dd, err := tm.Decode()
+	if err != nil {
+		t.Fatalf("Did not expect error but got %#v", err)
+	}
+	server := dd.mapData["server"].(map[string]interface{})
+	db := dd.mapData["database"].([]interface{})
+
+	tests := []struct {
+		tc       string
+		val      interface{}
+		dataType FieldDT
+		dtStruct string
+	}{
+		{"Small Integer", server["port"], Int, ""},
+		{"Large Integer", server["max_body"], Int64, ""},
+		{"Float", server["timeout"], Float64, ""},
+		{"Offset Date Time", dd.mapData["started"], Named, "time.Time"},
+		{"Local Date", dd.mapData["release"], Named, "time.Time"},
+		{"Local Time", dd.mapData["maintenance"], String, ""},
+		{"Array Of Tables", db, Slice, ""},
+		{"Table In Array", db[0], Map, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tc, func(t *testing.T) {
+			fld, err := ToField(tt.tc, tt.val)
+			if err != nil {
+				t.Fatalf("TC: %s: Did not expect error but got %#v", tt.tc, err)
+			}
+			if fld.dataType != tt.dataType || fld.dtStruct != tt.dtStruct {
+				t.Errorf("TC: %s: Expected (%v, %s) but got (%v, %s)", tt.tc,
+					tt.dataType, tt.dtStruct, fld.dataType, fld.dtStruct)
+			}
+		})
+	}
+}
+
+func TestParse_TOML(t *testing.T) {
+	if err := Parse(&TOML{File: "samples/toml/config.toml"}); err != nil {
+		t.Fatalf("Parse failed: %+v", err)
+	}
+	var buf bytes.Buffer
+	if err := WriteStructs(&buf); err != nil {
+		t.Fatalf("WriteStructs failed: %+v", err)
+	}
+	out := buf.String()
+	for _, exp := range []string{
+		"import (\n\t\"time\"\n)\n",
+		"\tStarted time.Time `toml:\"started\"`",
+		"\tRelease time.Time `toml:\"release\"`",
+		"\tMaintenance string `toml:\"maintenance\"`",
+		"\tServer Server `toml:\"server\"`",
+		"\tDatabase []Database `toml:\"database\"`",
+		"\tMaxBody int64 `toml:\"max_body\"`",
+		"\tPort int `toml:\"port\"`",
+		"\tReadonly bool `toml:\"readonly\"`",
+	} {
+		if !strings.Contains(out, exp) {
+			t.Errorf("Expected %q in the structs but got:\n%s", exp, out)
+		}
+	}
+}
@@ -1,10 +1,17 @@
 package togo
 
+import (
+	"encoding/json"
+)
+
 // DecodedData is the decoded data represented as a struct.
 // Data can be either decoded into a map[string]interface{} or a []interface{}.
+// Inputs holding more than one document (e.g. a YAML stream) decode each of
+// them into documents instead, all of which describe the same root type.
 type DecodedData struct {
 	mapData   map[string]interface{}
 	sliceData []interface{}
+	documents []DecodedData
 }
 
 // Decoder is an interface type which can be used by togo classes
@@ -13,6 +20,13 @@ type Decoder interface {
 	Decode() (DecodedData, error)
 }
 
+// Sourcer is implemented by decoders that know where their data comes from,
+// e.g. the name of the file they decode. The parser records the source on
+// every field so that it is known which inputs contributed a field.
+type Sourcer interface {
+	Source() string
+}
+
 // Annotater is an interface type that works on
 // annotating fields of a a go struct
 // FIXME: Is this required at all?
@@ -20,8 +34,74 @@ type Annotater interface {
 	Annotate(string) string
 }
 
+// KeyAnnotater is implemented by annotaters whose annotation of a key depends
+// on the parent of the key, i.e. the name of the struct the key becomes a
+// field of. It is preferred over Annotate.
+type KeyAnnotater interface {
+	AnnotateKey(parent, name string) string
+}
+
+// Commenter is implemented by decoders whose input can document the keys,
+// e.g. with comments. The comment of the key name inside the object that
+// becomes the struct parent is emitted as the doc comment of the field.
+type Commenter interface {
+	Comment(parent, name string) string
+}
+
+// StructNamer is implemented by decoders that name the structs of nested
+// objects themselves instead of after their key, e.g. to keep apart the
+// objects of unrelated bodies of an input that share a key. The object of key
+// inside the struct named parent becomes the struct of the returned name.
+type StructNamer interface {
+	StructName(parent, key string) string
+}
+
+// MixedTyper is implemented by decoders whose records do not have to agree
+// on the type of a field, e.g. the lines of an event log. A scalar field whose
+// types cannot be widened into one becomes interface{} instead of failing the
+// merge of the records.
+type MixedTyper interface {
+	MixedTypes() bool
+}
+
+// keyTexts holds a text per key, e.g. its comment, by the name of the parent
+// of the key, i.e. the name of the struct the key becomes a field of
+type keyTexts map[string]map[string]string
+
+// add the text of the key name of parent
+func (kt keyTexts) add(parent, name, text string) {
+	if kt[parent] == nil {
+		kt[parent] = make(map[string]string)
+	}
+	kt[parent][name] = text
+}
+
 // DecodeAnnotater is a composite Decode and Annotater interface
 type DecodeAnnotater interface {
 	Decoder
 	Annotater
 }
+
+// TokenStream is a stream of JSON tokens, as handed out by *json.Decoder.
+type TokenStream interface {
+	Token() (json.Token, error)
+	More() bool
+}
+
+// StreamDecoder is implemented by decoders that can hand their input over as
+// a TokenStream. The parser then infers the types token by token and the
+// memory needed stays proportional to the size of the inferred types rather
+// than to the size of the input.
+type StreamDecoder interface {
+	Decoder
+	Stream(fn func(TokenStream) error) error
+}
+
+// StructBuilder is implemented by decoders whose input describes the types
+// rather than giving samples of them, e.g. a schema. The parser then has the
+// decoder build the structs and cache them itself, starting with the root
+// struct of the tracker, instead of inferring them from decoded data.
+type StructBuilder interface {
+	Decoder
+	Build(tr tracker) (*GoStruct, error)
+}
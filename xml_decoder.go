@@ -0,0 +1,225 @@
+package togo
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"log"
+	"strings"
+)
+
+// Keys used in the decoded data of an XML document for what is not a child
+// element. Attributes are keyed by their name prefixed with attrPrefix.
+const (
+	attrPrefix  = "@"
+	charDataKey = "#text"
+	xmlNameKey  = "XMLName"
+)
+
+// XML type structure to convert to go struct. Child elements, attributes and
+// character data of an element are told apart in the decoded data so that
+// every field gets the right xml tag. Sibling elements with the same name
+// become a slice.
+type XML struct {
+	File   string
+	reader io.Reader
+	root   xml.Name
+	spaces map[string]string
+}
+
+// NewXMLReader creates an XML decoder reading from r instead of a file.
+// The reader is consumed by the first Decode.
+func NewXMLReader(r io.Reader) *XML {
+	return &XML{reader: r}
+}
+
+// NewXMLBytes creates an XML decoder for the in-memory data
+func NewXMLBytes(b []byte) *XML {
+	return NewXMLReader(bytes.NewReader(b))
+}
+
+// xmlNode is an element that is being decoded
+type xmlNode struct {
+	data map[string]interface{}
+	text strings.Builder
+}
+
+// value returns the decoded value of the element. An element without any
+// attribute or child is just its text.
+func (n *xmlNode) value() interface{} {
+	text := strings.TrimSpace(n.text.String())
+	if len(n.data) == 0 {
+		return text
+	}
+	if text != "" {
+		n.data[charDataKey] = text
+	}
+	return n.data
+}
+
+// add a child element to this element, turning repeated elements into a slice
+func (n *xmlNode) add(key string, val interface{}) {
+	ex, ok := n.data[key]
+	if !ok {
+		n.data[key] = val
+		return
+	}
+	if sl, ok := ex.([]interface{}); ok {
+		n.data[key] = append(sl, val)
+		return
+	}
+	n.data[key] = []interface{}{ex, val}
+}
+
+// Decode this XML instance into decodedData
+func (x *XML) Decode() (DecodedData, error) {
+
+	dd := new(DecodedData)
+	f, err := open(x.File, x.reader)
+	if err != nil {
+		log.Println("Error while reading file", err)
+		return *dd, err
+	}
+	defer f.Close()
+
+	x.spaces = make(map[string]string)
+	var stack []*xmlNode
+	var root interface{}
+	dec := xml.NewDecoder(f)
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Println("Error while decoding", err)
+			return *dd, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			n := &xmlNode{data: make(map[string]interface{})}
+			for _, a := range t.Attr {
+				if a.Name.Space == "xmlns" || a.Name.Local == "xmlns" {
+					continue
+				}
+				key := attrPrefix + a.Name.Local
+				n.data[key] = a.Value
+				x.space(key, a.Name.Space)
+			}
+			if len(stack) == 0 {
+				x.root = t.Name
+			} else {
+				x.space(t.Name.Local, t.Name.Space)
+			}
+			stack = append(stack, n)
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].text.Write(t)
+			}
+		case xml.EndElement:
+			n := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if len(stack) == 0 {
+				root = n.value()
+				continue
+			}
+			stack[len(stack)-1].add(t.Name.Local, n.value())
+		}
+	}
+
+	// An element that has attributes or children somewhere in the document
+	// is a struct everywhere, with the text of the plain occurrences as its
+	// character data.
+	complex := make(map[string]bool)
+	collectComplex(root, "", complex)
+	root = promoteText(root, "", complex)
+
+	switch v := root.(type) {
+	case map[string]interface{}:
+		dd.mapData = v
+	case string:
+		dd.mapData = map[string]interface{}{charDataKey: v}
+	default:
+		log.Println("No root element found in", x.File)
+		return *dd, errors.New("No root element to decode")
+	}
+	dd.mapData[xmlNameKey] = x.root
+	return *dd, nil
+}
+
+// collectComplex marks every element key under which a map is found
+func collectComplex(val interface{}, key string, complex map[string]bool) {
+	switch v := val.(type) {
+	case map[string]interface{}:
+		complex[key] = true
+		for k, e := range v {
+			collectComplex(e, k, complex)
+		}
+	case []interface{}:
+		for _, e := range v {
+			collectComplex(e, key, complex)
+		}
+	}
+}
+
+// promoteText turns the text of elements that are complex elsewhere into
+// a map holding the text as character data
+func promoteText(val interface{}, key string, complex map[string]bool) interface{} {
+	switch v := val.(type) {
+	case map[string]interface{}:
+		for k, e := range v {
+			v[k] = promoteText(e, k, complex)
+		}
+		return v
+	case []interface{}:
+		for i, e := range v {
+			v[i] = promoteText(e, key, complex)
+		}
+		return v
+	case string:
+		if complex[key] && !strings.HasPrefix(key, attrPrefix) && key != charDataKey {
+			return map[string]interface{}{charDataKey: v}
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// space records the namespace of the element or attribute with the given key
+func (x *XML) space(key, space string) {
+	if _, ok := x.spaces[key]; !ok && space != "" {
+		x.spaces[key] = space
+	}
+}
+
+// Source of this XML instance, the file name or empty if read from a reader
+func (x *XML) Source() string {
+	return x.File
+}
+
+// Annotate a field name with its xml tag. Attributes get the attr option,
+// character data the chardata option and namespaced names are qualified
+// with the namespace.
+func (x *XML) Annotate(name string) string {
+	if name == charDataKey {
+		return "xml:,chardata"
+	}
+	if name == xmlNameKey {
+		return "xml:" + qualify(x.root.Space, x.root.Local)
+	}
+	if strings.HasPrefix(name, attrPrefix) {
+		local := strings.TrimPrefix(name, attrPrefix)
+		return "xml:" + qualify(x.spaces[name], local) + ",attr"
+	}
+	return "xml:" + qualify(x.spaces[name], name)
+}
+
+// qualify a local name with its namespace the way encoding/xml tags do
+func qualify(space, local string) string {
+	if space == "" {
+		return local
+	}
+	return space + " " + local
+}
@@ -0,0 +1,48 @@
+package togo
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParse_XML(t *testing.T) {
+	x := &XML{File: "samples/xml/orders.xml"}
+	if err := Parse(x); err != nil {
+		t.Fatalf("Parse failed: %+v", err)
+	}
+	tests := []struct {
+		tc       string
+		gsName   string
+		field    string
+		dataType FieldDT
+		tag      string
+	}{
+		{"Root Name", "Document", "XMLName", Named, "xml:http://schemas.xmlsoap.org/soap/envelope/ Envelope"},
+		{"Namespaced Element", "Document", "Body", Map, "xml:http://schemas.xmlsoap.org/soap/envelope/ Body"},
+		{"Attribute", "Order", "@id", String, "xml:id,attr"},
+		{"Character Data", "Customer", "#text", String, "xml:,chardata"},
+		{"Repeated Element", "Orders", "Order", Slice, "xml:urn:partner:orders Order"},
+		{"Single And Repeated Element", "Order", "Item", Slice, "xml:urn:partner:orders Item"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tc, func(t *testing.T) {
+			gs, ok := NameStructCache[tt.gsName]
+			if !ok {
+				t.Fatalf("TC: %s: GoStruct %s was not generated", tt.tc, tt.gsName)
+			}
+			fld, ok := gs.Fields[tt.field]
+			if !ok {
+				t.Fatalf("TC: %s: Field %s missing in %s", tt.tc, tt.field, tt.gsName)
+			}
+			if fld.dataType != tt.dataType || fld.annotation != tt.tag {
+				t.Errorf("TC: %s: Expected (%v, %s) but got (%v, %s)", tt.tc,
+					tt.dataType, tt.tag, fld.dataType, fld.annotation)
+			}
+		})
+	}
+
+	str := NameStructCache["Item"].ToStruct()
+	if !strings.Contains(str, "Sku string `xml:\"sku,attr\"`") {
+		t.Errorf("Unexpected struct generated for Item: %s", str)
+	}
+}